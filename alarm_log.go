@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alarm-Protokoll (fxtray.alarms.jsonl, eine Zeile je Auslösung)
+
+const recentAlarmEvents = 200
+
+type alarmEvent struct {
+	Time     time.Time `json:"time"`
+	AlarmID  string    `json:"alarm_id"`
+	Pair     string    `json:"pair,omitempty"`
+	Rate     float64   `json:"rate,omitempty"`
+	Rule     string    `json:"rule"`
+	Message  string    `json:"message"`
+	Delivery string    `json:"delivery"`
+	Output   string    `json:"output,omitempty"`
+
+	// Bei Sammelmeldungen: die zurückgehaltenen Auslösungen
+	DigestOf []alarmRef `json:"digest_of,omitempty"`
+}
+
+// Verweis auf eine Auslösung (Alarm-ID und Zeitpunkt)
+type alarmRef struct {
+	AlarmID string    `json:"alarm_id"`
+	Time    time.Time `json:"time"`
+}
+
+// Letzte Einträge, älteste zuerst
+var (
+	alarmLogMu  sync.Mutex
+	alarmEvents []alarmEvent
+)
+
+func alarmLogPath() string {
+	return configSiblingPath(".alarms.jsonl")
+}
+
+// Letzte Einträge beim Start laden
+func loadAlarmLog() error {
+	events, err := readAlarmLog()
+	if err != nil {
+		return err
+	}
+	if len(events) > recentAlarmEvents {
+		events = events[len(events)-recentAlarmEvents:]
+	}
+
+	alarmLogMu.Lock()
+	alarmEvents = events
+	alarmLogMu.Unlock()
+	return nil
+}
+
+// Eintrag anhängen
+func logAlarmEvent(ev alarmEvent) error {
+	alarmLogMu.Lock()
+	defer alarmLogMu.Unlock()
+
+	alarmEvents = append(alarmEvents, ev)
+	if len(alarmEvents) > recentAlarmEvents {
+		alarmEvents = alarmEvents[len(alarmEvents)-recentAlarmEvents:]
+	}
+
+	f, err := os.OpenFile(alarmLogPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(ev)
+}
+
+// Letzte n Einträge, neueste zuerst
+func recentAlarms(n int) []alarmEvent {
+	alarmLogMu.Lock()
+	defer alarmLogMu.Unlock()
+
+	out := make([]alarmEvent, 0, min(n, len(alarmEvents)))
+	for i := len(alarmEvents) - 1; i >= 0 && len(out) < n; i-- {
+		out = append(out, alarmEvents[i])
+	}
+	return out
+}
+
+// Gesamtes Protokoll lesen
+func readAlarmLog() ([]alarmEvent, error) {
+	f, err := os.Open(alarmLogPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var events []alarmEvent
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var ev alarmEvent
+		if err := json.Unmarshal(sc.Bytes(), &ev); err != nil {
+			continue
+		}
+		events = append(events, ev)
+	}
+	return events, sc.Err()
+}
+
+// Export
+
+func exportAlarmLogCSV(path string) error {
+	events, err := readAlarmLog()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	_ = w.Write([]string{"time", "alarm_id", "pair", "rate", "rule", "message", "delivery", "output", "digest_of"})
+	for _, ev := range events {
+		rate := ""
+		if ev.Rate != 0 {
+			rate = strconv.FormatFloat(ev.Rate, 'f', -1, 64)
+		}
+		refs := make([]string, 0, len(ev.DigestOf))
+		for _, ref := range ev.DigestOf {
+			refs = append(refs, ref.AlarmID+"@"+ref.Time.Format(time.RFC3339))
+		}
+		_ = w.Write([]string{
+			ev.Time.Format(time.RFC3339), ev.AlarmID, ev.Pair, rate, ev.Rule, ev.Message, ev.Delivery, ev.Output,
+			strings.Join(refs, " "),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func exportAlarmLogJSON(path string) error {
+	events, err := readAlarmLog()
+	if err != nil {
+		return err
+	}
+	if events == nil {
+		events = []alarmEvent{}
+	}
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Einträge für das Tray-Menü
+func recentAlarmLines(n int) []string {
+	var lines []string
+	for _, ev := range recentAlarms(n) {
+		line := fmt.Sprintf("%s  %s", ev.Time.Local().Format("02.01. 15:04"), ev.Rule)
+		if ev.Rate != 0 {
+			line += fmt.Sprintf(" @ %.4f", ev.Rate)
+		}
+		switch {
+		case ev.Delivery == "ok":
+		case strings.HasPrefix(ev.Delivery, "queued"):
+			line += " (queued)"
+		default:
+			line += " (not delivered)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/getlantern/systray"
+)
+
+// Tray-Untermenü "Alarms": ein-/ausschalten und pausieren
+
+type alarmMenuEntry struct {
+	item          *systray.MenuItem
+	toggle        *systray.MenuItem
+	snoozeHour    *systray.MenuItem
+	snoozeDay     *systray.MenuItem
+	snoozeSession *systray.MenuItem
+	resume        *systray.MenuItem
+
+	alarm Alarm
+}
+
+type alarmMenu struct {
+	mu      sync.Mutex
+	parent  *systray.MenuItem
+	empty   *systray.MenuItem
+	entries []*alarmMenuEntry
+}
+
+func newAlarmMenu(parent *systray.MenuItem) *alarmMenu {
+	m := &alarmMenu{parent: parent}
+	m.empty = parent.AddSubMenuItem("No alarms configured", "")
+	m.empty.Disable()
+	return m
+}
+
+// Einträge aus der Config, überzählige ausblenden
+func (m *alarmMenu) update(cfg Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for i, a := range cfg.Alarms {
+		if i >= len(m.entries) {
+			m.entries = append(m.entries, m.newEntry())
+		}
+		e := m.entries[i]
+		e.alarm = a
+		e.item.SetTitle(fmt.Sprintf("%s [%s]", alarmLabel(a), alarmStatus(a, now)))
+		e.item.Show()
+		if a.Disabled {
+			e.toggle.Uncheck()
+		} else {
+			e.toggle.Check()
+		}
+		if !a.SnoozeUntil.IsZero() && now.Before(a.SnoozeUntil) {
+			e.resume.Enable()
+		} else {
+			e.resume.Disable()
+		}
+	}
+	for i := len(cfg.Alarms); i < len(m.entries); i++ {
+		m.entries[i].item.Hide()
+	}
+
+	if len(cfg.Alarms) == 0 {
+		m.empty.Show()
+	} else {
+		m.empty.Hide()
+	}
+}
+
+func (m *alarmMenu) newEntry() *alarmMenuEntry {
+	item := m.parent.AddSubMenuItem("", "")
+	e := &alarmMenuEntry{
+		item:          item,
+		toggle:        item.AddSubMenuItemCheckbox("Enabled", "Enable or disable this alarm", true),
+		snoozeHour:    item.AddSubMenuItem("Snooze 1 hour", ""),
+		snoozeDay:     item.AddSubMenuItem("Snooze 1 day", ""),
+		snoozeSession: item.AddSubMenuItem("Snooze until next session", ""),
+		resume:        item.AddSubMenuItem("Resume", "End snooze"),
+	}
+
+	go func() {
+		for {
+			var fn func(a *Alarm)
+			select {
+			case <-e.toggle.ClickedCh:
+				fn = func(a *Alarm) { a.Disabled = !a.Disabled }
+			case <-e.snoozeHour.ClickedCh:
+				fn = func(a *Alarm) { a.SnoozeUntil = time.Now().Add(time.Hour) }
+			case <-e.snoozeDay.ClickedCh:
+				fn = func(a *Alarm) { a.SnoozeUntil = time.Now().Add(24 * time.Hour) }
+			case <-e.snoozeSession.ClickedCh:
+				fn = func(a *Alarm) {
+					configMu.RLock()
+					cfg := currentConfig
+					configMu.RUnlock()
+					a.SnoozeUntil = nextSessionStart(cfg, time.Now())
+				}
+			case <-e.resume.ClickedCh:
+				fn = func(a *Alarm) { a.SnoozeUntil = time.Time{} }
+			}
+			m.apply(e, fn)
+		}
+	}()
+	return e
+}
+
+func (m *alarmMenu) apply(e *alarmMenuEntry, fn func(a *Alarm)) {
+	m.mu.Lock()
+	target := e.alarm
+	m.mu.Unlock()
+
+	if err := updateAlarm(target, fn); err != nil {
+		fmt.Println("alarm menu:", err)
+	}
+
+	configMu.RLock()
+	cfg := currentConfig
+	configMu.RUnlock()
+	m.update(cfg)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Auslösezustand je Alarm-ID (fxtray.state.json)
+
+type alarmState struct {
+	// Regel beim letzten Stand; eine geänderte Regel beginnt ohne Zustand
+	Rule      string    `json:"rule"`
+	LastFired time.Time `json:"last_fired,omitzero"`
+	LastValue float64   `json:"last_value,omitempty"`
+
+	// Kreuzungsalarme: Seite zum Ziel ("above"/"below"), bestätigt jenseits der Hysterese
+	Side      string `json:"side,omitempty"`
+	Confirmed bool   `json:"confirmed,omitempty"`
+}
+
+var (
+	alarmStatesMu    sync.Mutex
+	alarmStates      = map[string]*alarmState{}
+	alarmStatesDirty bool
+)
+
+func alarmStatePath() string {
+	return configSiblingPath(".state.json")
+}
+
+// Zustand laden
+func loadAlarmStates() error {
+	data, err := os.ReadFile(alarmStatePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	states := map[string]*alarmState{}
+	if err := json.Unmarshal(data, &states); err != nil {
+		return err
+	}
+
+	alarmStatesMu.Lock()
+	alarmStates = states
+	alarmStatesMu.Unlock()
+	return nil
+}
+
+// Zustand speichern, falls geändert
+func saveAlarmStates() error {
+	alarmStatesMu.Lock()
+	defer alarmStatesMu.Unlock()
+
+	if !alarmStatesDirty {
+		return nil
+	}
+	data, err := json.MarshalIndent(alarmStates, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(alarmStatePath(), data, 0644); err != nil {
+		return err
+	}
+	alarmStatesDirty = false
+	return nil
+}
+
+// Zustand eines Alarms (Aufrufer hält alarmStatesMu)
+func stateFor(a Alarm) *alarmState {
+	rule := alarmRule(a)
+	st, ok := alarmStates[a.ID]
+	if !ok || st.Rule != rule {
+		st = &alarmState{Rule: rule}
+		alarmStates[a.ID] = st
+		alarmStatesDirty = true
+	}
+	return st
+}
+
+// Fingerprint der auslösenden Regel (ohne Lebenszyklus-Felder)
+func alarmRule(a Alarm) string {
+	return fmt.Sprintf("%s|%s|%g|%s|%g|%g|%s",
+		normalizeAlarmPair(a.Pair), a.Direction, a.Target, a.Reference, a.WindowHours, a.Hysteresis, a.Expression)
+}
+
+// Zustand gelöschter Alarme entfernen
+func pruneAlarmStates(cfg Config) {
+	ids := map[string]bool{}
+	for _, a := range cfg.Alarms {
+		ids[a.ID] = true
+	}
+
+	alarmStatesMu.Lock()
+	defer alarmStatesMu.Unlock()
+	for id := range alarmStates {
+		if !ids[id] {
+			delete(alarmStates, id)
+			alarmStatesDirty = true
+		}
+	}
+}
+
+// IDs
+
+func newAlarmID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// Fehlende IDs vergeben; true, wenn etwas geändert wurde
+func ensureAlarmIDs(cfg *Config) bool {
+	changed := false
+	seen := map[string]bool{}
+	for i := range cfg.Alarms {
+		if cfg.Alarms[i].ID == "" || seen[cfg.Alarms[i].ID] {
+			cfg.Alarms[i].ID = newAlarmID()
+			changed = true
+		}
+		seen[cfg.Alarms[i].ID] = true
+	}
+	return changed
+}
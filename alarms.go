@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alarm-Richtungen
+const (
+	dirAbove       = "above"
+	dirBelow       = "below"
+	dirChangeUp    = "change_pct_up"
+	dirChangeDown  = "change_pct_down"
+	dirCrossesUp   = "crosses_up"
+	dirCrossesDown = "crosses_down"
+	dirCrosses     = "crosses"
+	dirExpression  = "expression"
+)
+
+// Bezugspunkte für prozentuale Alarme
+const (
+	refPrevClose = "prev_close"
+	refCreated   = "created"
+	refWindow    = "window"
+)
+
+var (
+	alarmDirections = []string{
+		dirAbove, dirBelow, dirChangeUp, dirChangeDown, dirCrossesUp, dirCrossesDown, dirCrosses,
+		dirExpression,
+	}
+	alarmReferences = []string{refPrevClose, refCreated, refWindow}
+)
+
+func isChangeDirection(dir string) bool {
+	return dir == dirChangeUp || dir == dirChangeDown
+}
+
+func isCrossDirection(dir string) bool {
+	return dir == dirCrossesUp || dir == dirCrossesDown || dir == dirCrosses
+}
+
+// Alarm
+
+// latest: Kurse dieses Durchlaufs; all: alle aktuellen Kurse (für Ausdrücke über mehrere Paare)
+func checkAlarms(cfg Config, latest, all map[string]float64) {
+	now := time.Now()
+	var fired []Notification
+
+	for _, a := range cfg.Alarms {
+		if !alarmActive(a, now) {
+			continue
+		}
+		dir := strings.ToLower(strings.TrimSpace(a.Direction))
+
+		// Ausdrücke über mehrere Paare
+		if dir == dirExpression {
+			fire, ok, err := evalAlarmExpression(a.Expression, all)
+			if err != nil {
+				fmt.Println("alarm expression:", err)
+			}
+			if ok && fire && canTriggerAlarm(a, now) {
+				fired = append(fired, fireAlarm(cfg, a, alarmMessage{
+					Kind:       kindExpression,
+					Expression: a.Expression,
+				}, now))
+			}
+			continue
+		}
+
+		key := normalizeAlarmPair(a.Pair)
+		rate, ok := latest[key]
+		if !ok {
+			continue
+		}
+
+		shouldFire := false
+		cooldown := true
+		m := alarmMessage{
+			Kind:      kindLevel,
+			Pair:      key,
+			Rate:      rate,
+			Target:    a.Target,
+			Direction: dir,
+		}
+		switch dir {
+		case dirAbove:
+			shouldFire = rate >= a.Target
+		case dirBelow:
+			shouldFire = rate <= a.Target
+		case dirChangeUp, dirChangeDown:
+			if referenceKind(a) == refCreated && a.BaseRate == 0 {
+				// Ohne base_rate (z.B. in fxtray.json angelegt): ersten Kurs als Bezug festhalten
+				if err := updateAlarm(a, func(a *Alarm) { a.BaseRate = rate }); err != nil {
+					fmt.Println("record base rate:", err)
+				}
+				continue
+			}
+			if referenceKind(a) != refCreated && cfg.History.Disabled {
+				warnOnce("history:"+a.ID, "alarm "+alarmLabel(a)+": "+referenceLabel(a)+
+					" needs the rate history, which is disabled")
+				continue
+			}
+			ref, ok := referenceRate(a, key, now)
+			if !ok || ref == 0 {
+				continue
+			}
+			change := (rate - ref) / ref * 100
+			if dir == dirChangeUp {
+				shouldFire = change >= math.Abs(a.Target)
+			} else {
+				shouldFire = change <= -math.Abs(a.Target)
+			}
+			m.Kind = kindChange
+			m.Change = change
+			m.Reference = referenceKind(a)
+			m.ReferenceRate = ref
+			m.WindowHours = a.WindowHours
+		case dirCrossesUp, dirCrossesDown, dirCrosses:
+			// Flankengesteuert, daher ohne Cooldown
+			shouldFire, m.Crossed = evalCross(a, dir, rate, math.Abs(a.Hysteresis))
+			cooldown = false
+			m.Kind = kindCross
+		default:
+			continue
+		}
+
+		if !shouldFire {
+			continue
+		}
+		if cooldown && !canTriggerAlarm(a, now) {
+			continue
+		}
+		fired = append(fired, fireAlarm(cfg, a, m, now))
+	}
+
+	if err := saveAlarmStates(); err != nil {
+		fmt.Println("saveAlarmStates:", err)
+	}
+	dispatchNotifications(cfg, fired)
+}
+
+// Auslösung vermerken, Einmal-Alarme ausschalten; Zustellung über die Kanäle des Alarms
+func fireAlarm(cfg Config, a Alarm, m alarmMessage, now time.Time) Notification {
+	alarmStatesMu.Lock()
+	st := stateFor(a)
+	st.LastFired = now
+	st.LastValue = m.Rate
+	alarmStatesDirty = true
+	alarmStatesMu.Unlock()
+
+	if a.OneShot {
+		if err := updateAlarm(a, func(a *Alarm) { a.Disabled = true }); err != nil {
+			fmt.Println("disable one-shot alarm:", err)
+		}
+	}
+
+	m.Rule = alarmLabel(a)
+	m.Time = now
+	title, msg := renderAlarmMessage(cfg, a, m)
+
+	return Notification{
+		AlarmID:   a.ID,
+		Rule:      m.Rule,
+		Pair:      normalizeAlarmPair(a.Pair),
+		Rate:      m.Rate,
+		Target:    a.Target,
+		Direction: a.Direction,
+		Title:     title,
+		Message:   msg,
+		Time:      now,
+		Channels:  alarmChannels(a),
+	}
+}
+
+// Hinweise zu Alarmen nur einmal je Lauf ausgeben
+var (
+	warnedMu sync.Mutex
+	warned   = map[string]bool{}
+)
+
+func warnOnce(key, msg string) {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if warned[key] {
+		return
+	}
+	warned[key] = true
+	fmt.Println(msg)
+}
+
+// Lebenszyklus
+
+// Eingeschaltet, nicht abgelaufen, nicht pausiert
+func alarmActive(a Alarm, now time.Time) bool {
+	if a.Disabled {
+		return false
+	}
+	if !a.ExpiresAt.IsZero() && !now.Before(a.ExpiresAt) {
+		return false
+	}
+	return a.SnoozeUntil.IsZero() || !now.Before(a.SnoozeUntil)
+}
+
+func alarmStatus(a Alarm, now time.Time) string {
+	switch {
+	case a.Disabled:
+		return "off"
+	case !a.ExpiresAt.IsZero() && !now.Before(a.ExpiresAt):
+		return "expired"
+	case !a.SnoozeUntil.IsZero() && now.Before(a.SnoozeUntil):
+		return "snoozed until " + a.SnoozeUntil.Local().Format("02.01. 15:04")
+	case a.OneShot:
+		return "on (once)"
+	default:
+		return "on"
+	}
+}
+
+// Kurzbeschreibung für Menüs
+func alarmLabel(a Alarm) string {
+	switch {
+	case a.Direction == dirExpression:
+		return a.Expression
+	case isChangeDirection(a.Direction):
+		return fmt.Sprintf("%s %s %.2f%%", a.Pair, a.Direction, a.Target)
+	default:
+		return fmt.Sprintf("%s %s %.4f", a.Pair, a.Direction, a.Target)
+	}
+}
+
+// Derselbe Alarm (Konfiguration kann inzwischen neu geladen sein)
+func sameAlarm(a, b Alarm) bool {
+	return a.ID != "" && a.ID == b.ID
+}
+
+// Cooldown seit der letzten Auslösung abgelaufen
+func canTriggerAlarm(a Alarm, now time.Time) bool {
+	alarmStatesMu.Lock()
+	defer alarmStatesMu.Unlock()
+
+	st := stateFor(a)
+	return st.LastFired.IsZero() || now.Sub(st.LastFired) >= alarmCooldown
+}
+
+// Kreuzung des Ziels in Richtung dir; Rauschen innerhalb von Ziel ± Hysterese löst nicht erneut aus
+func evalCross(a Alarm, dir string, rate, hysteresis float64) (bool, string) {
+	alarmStatesMu.Lock()
+	defer alarmStatesMu.Unlock()
+
+	st := stateFor(a)
+	side, confirmed, crossed := crossStep(st.Side, st.Confirmed, rate, a.Target, hysteresis)
+	if side != st.Side || confirmed != st.Confirmed {
+		st.Side, st.Confirmed = side, confirmed
+		alarmStatesDirty = true
+	}
+
+	switch {
+	case crossed == "":
+		return false, ""
+	case crossed == "above" && dir == dirCrossesDown, crossed == "below" && dir == dirCrossesUp:
+		return false, crossed
+	default:
+		return true, crossed
+	}
+}
+
+// Seite des Kurses zum Ziel, bestätigt jenseits von Ziel ± Hysterese. Am Ziel wechselt
+// (und kreuzt) die Seite nur, wenn sie bestätigt war oder der Kurs das ganze Band durchquert.
+func crossStep(side string, confirmed bool, rate, target, hysteresis float64) (string, bool, string) {
+	farAbove := rate > target+hysteresis
+	farBelow := rate < target-hysteresis
+
+	switch side {
+	case "above":
+		if rate <= target && (confirmed || farBelow) {
+			return "below", farBelow, "below"
+		}
+		return side, confirmed || farAbove, ""
+	case "below":
+		if rate >= target && (confirmed || farAbove) {
+			return "above", farAbove, "above"
+		}
+		return side, confirmed || farBelow, ""
+	default:
+		// Erster Kurs: nur Ausgangslage merken
+		if rate > target {
+			return "above", farAbove, ""
+		}
+		return "below", farBelow, ""
+	}
+}
+
+// Bezugskurs für prozentuale Alarme
+func referenceRate(a Alarm, key string, now time.Time) (float64, bool) {
+	switch strings.ToLower(a.Reference) {
+	case refCreated:
+		return a.BaseRate, a.BaseRate != 0
+	case refWindow:
+		if a.WindowHours <= 0 {
+			return 0, false
+		}
+		from := now.Add(-time.Duration(a.WindowHours * float64(time.Hour)))
+		if pt, ok := historyAt(key, from); ok {
+			return pt.Rate, true
+		}
+		// Historie kürzer als das Fenster: ältester Punkt im Fenster
+		if pts := historySince(key, from); len(pts) > 0 {
+			return pts[0].Rate, true
+		}
+		return 0, false
+	default:
+		pt, ok := historyAt(key, previousClose(now))
+		return pt.Rate, ok
+	}
+}
+
+// Letzter Tagesschluss (17:00 New York)
+func previousClose(now time.Time) time.Time {
+	ny := now.In(newYork)
+	closeTime := time.Date(ny.Year(), ny.Month(), ny.Day(), marketCloseHour, 0, 0, 0, newYork)
+	if ny.Before(closeTime) {
+		closeTime = closeTime.AddDate(0, 0, -1)
+	}
+	return closeTime
+}
+
+// Bezugspunkt für Vorlagen, Standard Vortagesschluss
+func referenceKind(a Alarm) string {
+	switch ref := strings.ToLower(a.Reference); ref {
+	case refCreated, refWindow:
+		return ref
+	default:
+		return refPrevClose
+	}
+}
+
+func referenceLabel(a Alarm) string {
+	switch strings.ToLower(a.Reference) {
+	case refCreated:
+		return "creation"
+	case refWindow:
+		return fmt.Sprintf("%gh ago", a.WindowHours)
+	default:
+		return "prev. close"
+	}
+}
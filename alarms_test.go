@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestEvalCross(t *testing.T) {
+	tests := []struct {
+		name       string
+		dir        string
+		hysteresis float64
+		rates      []float64
+		want       []string // Kreuzungen, die auslösen
+	}{
+		{"up once", dirCrossesUp, 0, []float64{0.99, 1.001, 1.002}, []string{"above"}},
+		{"first rate only sets side", dirCrosses, 0, []float64{1.01, 1.02}, nil},
+		{"down through target", dirCrossesDown, 0, []float64{1.01, 1.0}, []string{"below"}},
+		{"repeated target quote", dirCrosses, 0, []float64{0.99, 1.0, 1.0, 1.0}, []string{"above"}},
+		{"both ways without hysteresis", dirCrosses, 0, []float64{0.99, 1.01, 0.99, 1.01}, []string{"above", "below", "above"}},
+		{"reversal through band", dirCrosses, 0.005, []float64{0.99, 1.001, 1.002, 0.98}, []string{"above", "below"}},
+		{"noise inside band", dirCrosses, 0.005, []float64{0.99, 1.001, 0.999, 1.001, 0.999}, []string{"above"}},
+		{"re-arms beyond band", dirCrosses, 0.005, []float64{0.99, 1.001, 1.01, 0.999, 0.99, 1.001}, []string{"above", "below", "above"}},
+		{"start inside band needs full move", dirCrosses, 0.005, []float64{1.001, 0.999, 1.003, 0.99}, []string{"below"}},
+		{"up alarm ignores down crossing", dirCrossesUp, 0.005, []float64{1.01, 0.98, 1.02}, []string{"above"}},
+		{"down alarm ignores up crossing", dirCrossesDown, 0.005, []float64{0.98, 1.02, 0.98}, []string{"below"}},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := Alarm{
+				ID:         fmt.Sprintf("cross-test-%d", i),
+				Pair:       "EUR/USD",
+				Target:     1.0,
+				Direction:  tt.dir,
+				Hysteresis: tt.hysteresis,
+			}
+			t.Cleanup(func() {
+				alarmStatesMu.Lock()
+				delete(alarmStates, a.ID)
+				alarmStatesMu.Unlock()
+			})
+
+			var got []string
+			for _, rate := range tt.rates {
+				if fired, crossed := evalCross(a, tt.dir, rate, tt.hysteresis); fired {
+					got = append(got, crossed)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rates %v: fired %v, want %v", tt.rates, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// Kurs-Cache für Offline-Start (fxtray.cache.json)
+
+const defaultAlarmMaxAge = 60 * time.Minute
+
+var errCachedRate = errors.New("cached rate, not refreshed yet")
+
+// Cache-Datei neben der Config
+func rateCachePath() string {
+	return configSiblingPath(".cache.json")
+}
+
+// Höchstalter von Cache-Kursen für Alarme
+func alarmMaxAge(cfg Config) time.Duration {
+	if cfg.AlarmMaxAgeMinutes > 0 {
+		return time.Duration(cfg.AlarmMaxAgeMinutes) * time.Minute
+	}
+	return defaultAlarmMaxAge
+}
+
+// Cache speichern
+func saveRateCache() error {
+	tableCacheMu.Lock()
+	data, err := json.MarshalIndent(tableCache, "", "  ")
+	tableCacheMu.Unlock()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(rateCachePath(), data, 0644)
+}
+
+// Cache laden
+func loadRateCache() error {
+	data, err := os.ReadFile(rateCachePath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var tables map[string]*RateTable
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return err
+	}
+
+	tableCacheMu.Lock()
+	for key, rt := range tables {
+		if rt == nil || rt.Rates == nil {
+			continue
+		}
+		rt.FromDisk = true
+		tableCache[key] = rt
+	}
+	tableCacheMu.Unlock()
+	return nil
+}
+
+// Kursstatus aus dem Cache vorbelegen, alle Werte gelten als veraltet
+func seedPairStatesFromCache(cfg Config) map[string]pairState {
+	provider, err := newRateProvider(cfg)
+	if err != nil {
+		return nil
+	}
+
+	states := map[string]pairState{}
+	tableCacheMu.Lock()
+	for base, pairs := range fetchPlan(cfg) {
+		rt, ok := tableCache[provider.Name()+":"+base]
+		if !ok {
+			continue
+		}
+		for _, p := range pairs {
+			if rate, ok := crossRate(rt, p.From, p.To); ok {
+				states[pairKey(p.From, p.To)] = pairState{
+					Rate:    rate,
+					Updated: rt.Fetched,
+					AsOf:    rt.AsOf,
+					Cached:  true,
+					Err:     errCachedRate,
+				}
+			}
+		}
+	}
+	tableCacheMu.Unlock()
+
+	setPairStates(states)
+	return states
+}
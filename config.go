@@ -2,36 +2,147 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 )
 
 //Definitionen
 
 // Währungspaar definition
 type CurrencyPair struct {
-	From string `json:"from"`
-	To   string `json:"to"`
+	From           string `json:"from"`
+	To             string `json:"to"`
+	Direct         bool   `json:"direct,omitempty"`
+	RefreshSeconds int    `json:"refresh_seconds,omitempty"`
 }
 
-// Alarm definition
+// Alarm definition; bei change_pct_* ist Target die Veränderung in Prozent
 type Alarm struct {
-	Pair      string  `json:"pair"`
-	Target    float64 `json:"target"`
-	Direction string  `json:"direction"`
+	ID          string    `json:"id"`
+	Pair        string    `json:"pair"`
+	Target      float64   `json:"target"`
+	Direction   string    `json:"direction"`
+	Reference   string    `json:"reference,omitempty"`
+	WindowHours float64   `json:"window_hours,omitempty"`
+	BaseRate    float64   `json:"base_rate,omitempty"`
+	Hysteresis  float64   `json:"hysteresis,omitempty"`
+	Expression  string    `json:"expression,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	OneShot     bool      `json:"one_shot,omitempty"`
+	Disabled    bool      `json:"disabled,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitzero"`
+	SnoozeUntil time.Time `json:"snooze_until,omitzero"`
+	Channels    []string  `json:"channels,omitempty"`
+
+	// Eigene Vorlagen für diesen Alarm (leer = global bzw. eingebaut)
+	TitleTemplate   string `json:"title_template,omitempty"`
+	MessageTemplate string `json:"message_template,omitempty"`
+}
+
+// Benachrichtigungskanal definition
+type ChannelConfig struct {
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	Retries        *int   `json:"retries,omitempty"`         // fehlt = Standard, 0 = keine Wiederholung
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"` // email, command
+
+	// webhook
+	URL             string            `json:"url,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Template        string            `json:"template,omitempty"`
+	Secret          string            `json:"secret,omitempty"`
+	SignatureHeader string            `json:"signature_header,omitempty"`
+
+	// email
+	Host            string   `json:"host,omitempty"`
+	Port            int      `json:"port,omitempty"`
+	Username        string   `json:"username,omitempty"`
+	Password        string   `json:"password,omitempty"`
+	From            string   `json:"from,omitempty"`
+	To              []string `json:"to,omitempty"`
+	DisableStartTLS bool     `json:"disable_starttls,omitempty"`
+
+	// ntfy (Username/Password auch hier)
+	Server   string   `json:"server,omitempty"`
+	Topic    string   `json:"topic,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Token    string   `json:"token,omitempty"`
+
+	// command
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+// Konsens definition (Median mehrerer Quellen)
+type ConsensusConfig struct {
+	Pairs        []string `json:"pairs"`
+	Providers    []string `json:"providers"`
+	ThresholdBps float64  `json:"threshold_bps,omitempty"`
+}
+
+// HTTP-Client definition
+type HTTPConfig struct {
+	TimeoutSeconds   int    `json:"timeout_seconds,omitempty"`
+	Retries          *int   `json:"retries,omitempty"` // fehlt = 2, 0 = keine Wiederholung
+	BackoffMillis    int    `json:"backoff_ms,omitempty"`
+	MaxBackoffMillis int    `json:"max_backoff_ms,omitempty"`
+	ProxyURL         string `json:"proxy_url,omitempty"`
+	CABundle         string `json:"ca_bundle,omitempty"`
+	UserAgent        string `json:"user_agent,omitempty"`
+}
+
+// Handelszeiten definition (Feiertage als JJJJ-MM-TT, New Yorker Datum)
+type MarketConfig struct {
+	AlwaysOpen bool     `json:"always_open,omitempty"`
+	Holidays   []string `json:"holidays,omitempty"`
+}
+
+// Kurshistorie definition
+type HistoryConfig struct {
+	Disabled  bool `json:"disabled,omitempty"`
+	RawDays   int  `json:"raw_days,omitempty"`
+	DailyDays int  `json:"daily_days,omitempty"`
 }
 
 // Config definition
 type Config struct {
-	Pairs  []CurrencyPair `json:"pairs"`
-	Alarms []Alarm        `json:"alarms"`
+	Provider               string            `json:"provider,omitempty"`
+	Providers              []string          `json:"providers,omitempty"`
+	ProviderURLs           map[string]string `json:"provider_urls,omitempty"`
+	ProviderCooloffMinutes int               `json:"provider_cooloff_minutes,omitempty"`
+	RefreshSeconds         int               `json:"refresh_seconds,omitempty"`
+	Market                 MarketConfig      `json:"market"`
+	History                HistoryConfig     `json:"history"`
+	Consensus              *ConsensusConfig  `json:"consensus,omitempty"`
+	Triangulate            bool              `json:"triangulate,omitempty"`
+	TriangulationBase      string            `json:"triangulation_base,omitempty"`
+	HTTP                   HTTPConfig        `json:"http"`
+	AlarmMaxAgeMinutes     int               `json:"alarm_max_age_minutes,omitempty"`
+	Messages               MessageConfig     `json:"messages"`
+	Quiet                  QuietConfig       `json:"quiet"`
+	Channels               []ChannelConfig   `json:"channels,omitempty"`
+	Pairs                  []CurrencyPair    `json:"pairs"`
+	Alarms                 []Alarm           `json:"alarms"`
 }
 
-// Antwort API https://open.er-api.com/v6/latest/{BASE}
-type rateResponse struct {
-	Result   string             `json:"result"`
-	BaseCode string             `json:"base_code"`
-	Rates    map[string]float64 `json:"rates"`
+// Sprache und Vorlagen der Alarm-Meldungen
+type MessageConfig struct {
+	Locale          string `json:"locale,omitempty"`
+	TitleTemplate   string `json:"title_template,omitempty"`
+	MessageTemplate string `json:"message_template,omitempty"`
+}
+
+// Ruhezeiten und Stundenlimit für Benachrichtigungen
+type QuietConfig struct {
+	Start      string   `json:"start,omitempty"`
+	End        string   `json:"end,omitempty"`
+	Days       []string `json:"days,omitempty"`
+	Channels   []string `json:"channels,omitempty"`
+	MaxPerHour int      `json:"max_per_hour,omitempty"`
 }
 
 // Config-Datei
@@ -46,10 +157,18 @@ func defaultConfigPath() string {
 	return filepath.Join(dir, "fxtray.json")
 }
 
+// Weitere Dateien neben der Config, z.B. fxtray.cache.json
+func configSiblingPath(suffix string) string {
+	dir := filepath.Dir(configPath)
+	name := strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath))
+	return filepath.Join(dir, name+suffix)
+}
+
 // Config anlegen
 func ensureConfig() error {
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		def := Config{
+			Provider: defaultProvider,
 			Pairs: []CurrencyPair{
 				{From: "CHF", To: "EUR"},
 				{From: "EUR", To: "CHF"},
@@ -75,6 +194,12 @@ func loadConfig() error {
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return err
 	}
+
+	// Alarme ohne ID (ältere Config) erhalten eine feste ID
+	if ensureAlarmIDs(&cfg) {
+		return saveConfig(cfg)
+	}
+
 	configMu.Lock()
 	currentConfig = cfg
 	configMu.Unlock()
@@ -83,6 +208,9 @@ func loadConfig() error {
 
 // Config aktualisieren
 func saveConfig(cfg Config) error {
+	ensureAlarmIDs(&cfg)
+	pruneAlarmStates(cfg)
+
 	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
@@ -93,5 +221,24 @@ func saveConfig(cfg Config) error {
 	configMu.Lock()
 	currentConfig = cfg
 	configMu.Unlock()
+
+	// Neue Intervalle sofort anwenden
+	requestReschedule()
 	return nil
 }
+
+// Einzelnen Alarm ändern und speichern
+func updateAlarm(match Alarm, fn func(a *Alarm)) error {
+	configMu.RLock()
+	cfg := currentConfig
+	cfg.Alarms = append([]Alarm(nil), currentConfig.Alarms...)
+	configMu.RUnlock()
+
+	for i := range cfg.Alarms {
+		if sameAlarm(cfg.Alarms[i], match) {
+			fn(&cfg.Alarms[i])
+			return saveConfig(cfg)
+		}
+	}
+	return errors.New("alarm no longer exists")
+}
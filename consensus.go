@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Konsenskurse über mehrere Quellen
+
+const defaultConsensusThresholdBps = 50
+
+// Kurse je Quelle für ein Paar
+type consensusQuote struct {
+	provider string
+	rate     float64
+}
+
+// Median je Konsens-Paar; bei Abweichung über dem Schwellwert Warnung
+func consensusRates(cfg Config) map[string]float64 {
+	cc := cfg.Consensus
+	if cc == nil || len(cc.Pairs) == 0 || len(cc.Providers) == 0 {
+		return nil
+	}
+
+	// Paare nach Basiswährung gruppieren
+	byBase := map[string][]string{}
+	for _, pair := range cc.Pairs {
+		key := normalizeAlarmPair(pair)
+		from, to, ok := strings.Cut(key, "/")
+		if !ok {
+			continue
+		}
+		byBase[from] = append(byBase[from], to)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	quotes := map[string][]consensusQuote{}
+
+	for _, name := range cc.Providers {
+		p, err := providerByName(cfg, name)
+		if err != nil {
+			fmt.Println("consensus:", err)
+			continue
+		}
+		wg.Add(1)
+		go func(p RateProvider) {
+			defer wg.Done()
+			for base, targets := range byBase {
+				rt, err := p.Latest(base)
+				if err != nil {
+					fmt.Printf("consensus %s: %v\n", p.Name(), err)
+					continue
+				}
+				mu.Lock()
+				for _, to := range targets {
+					if rate, ok := rt.Rates[to]; ok {
+						key := pairKey(base, to)
+						quotes[key] = append(quotes[key], consensusQuote{provider: p.Name(), rate: rate})
+					}
+				}
+				mu.Unlock()
+			}
+		}(p)
+	}
+	wg.Wait()
+
+	threshold := cc.ThresholdBps
+	if threshold <= 0 {
+		threshold = defaultConsensusThresholdBps
+	}
+
+	out := map[string]float64{}
+	for key, qs := range quotes {
+		median := medianQuote(qs)
+		out[key] = median
+
+		if len(qs) < 2 || median == 0 {
+			continue
+		}
+		lo, hi := qs[0], qs[len(qs)-1]
+		spread := (hi.rate - lo.rate) / median * 10000
+		if spread > threshold {
+			notifyDivergence(cfg, key, lo, hi, spread)
+		}
+	}
+	return out
+}
+
+// Median, sortiert qs aufsteigend
+func medianQuote(qs []consensusQuote) float64 {
+	sort.Slice(qs, func(i, j int) bool { return qs[i].rate < qs[j].rate })
+	n := len(qs)
+	if n%2 == 1 {
+		return qs[n/2].rate
+	}
+	return (qs[n/2-1].rate + qs[n/2].rate) / 2
+}
+
+func notifyDivergence(cfg Config, key string, lo, hi consensusQuote, spread float64) {
+	now := time.Now()
+	trigKey := "divergence:" + key
+
+	triggeredMu.Lock()
+	lastTime, exists := lastTriggered[trigKey]
+	if exists && now.Sub(lastTime) < alarmCooldown {
+		triggeredMu.Unlock()
+		return
+	}
+	lastTriggered[trigKey] = now
+	triggeredMu.Unlock()
+
+	msg := fmt.Sprintf("%s providers disagree by %.0f bp (%s %.4f, %s %.4f)",
+		key, spread, lo.provider, lo.rate, hi.provider, hi.rate)
+	notifyDesktop(cfg, "FX Divergence", msg)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/Knetic/govaluate.v3"
+)
+
+// Alarm-Ausdrücke, z.B. "EURCHF < 0.93 && USDCHF > 0.88" oder "GBPUSD / EURUSD > 1.17"
+
+var pairVarPattern = regexp.MustCompile(`^[A-Z]{6}$`)
+
+// Funktionen in Ausdrücken
+var expressionFunctions = map[string]govaluate.ExpressionFunction{
+	"abs": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("abs expects 1 argument")
+		}
+		v, ok := args[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("abs expects a number")
+		}
+		if v < 0 {
+			v = -v
+		}
+		return v, nil
+	},
+	"min": func(args ...interface{}) (interface{}, error) {
+		return foldNumbers("min", args, func(a, b float64) bool { return b < a })
+	},
+	"max": func(args ...interface{}) (interface{}, error) {
+		return foldNumbers("max", args, func(a, b float64) bool { return b > a })
+	},
+}
+
+// Übersetzte Ausdrücke
+var (
+	expressionMu    sync.Mutex
+	expressionCache = map[string]*govaluate.EvaluableExpression{}
+)
+
+// Ausdruck prüfen: Syntax und Variablen (Paare als EURCHF)
+func compileAlarmExpression(expr string) (*govaluate.EvaluableExpression, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+
+	expressionMu.Lock()
+	defer expressionMu.Unlock()
+	if e, ok := expressionCache[expr]; ok {
+		return e, nil
+	}
+
+	e, err := govaluate.NewEvaluableExpressionWithFunctions(expr, expressionFunctions)
+	if err != nil {
+		return nil, err
+	}
+	vars := e.Vars()
+	if len(vars) == 0 {
+		return nil, fmt.Errorf("expression does not reference any pair")
+	}
+	for _, v := range vars {
+		if !pairVarPattern.MatchString(v) {
+			return nil, fmt.Errorf("unknown variable %q (use pairs like EURCHF)", v)
+		}
+	}
+
+	expressionCache[expr] = e
+	return e, nil
+}
+
+// Ausdruck prüfen und alle Variablen gegen die Paare (oder deren Kehrwert) abgleichen
+func validateAlarmExpression(expr string, pairs []string) error {
+	e, err := compileAlarmExpression(expr)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, pair := range pairs {
+		from, to, found := strings.Cut(normalizeAlarmPair(pair), "/")
+		if !found {
+			continue
+		}
+		known[from+to] = true
+		known[to+from] = true
+	}
+	for _, v := range e.Vars() {
+		if !known[v] {
+			return fmt.Errorf("%s/%s is not a configured pair", v[:3], v[3:])
+		}
+	}
+	return nil
+}
+
+// Paare, die in Ausdrücken verfügbar sind (auch Konsens-Paare)
+func expressionPairs(cfg Config) []string {
+	var pairs []string
+	for _, p := range cfg.Pairs {
+		pairs = append(pairs, pairKey(p.From, p.To))
+	}
+	if cfg.Consensus != nil {
+		pairs = append(pairs, cfg.Consensus.Pairs...)
+	}
+	return pairs
+}
+
+// Ausdruck mit aktuellen Kursen auswerten; ok=false, wenn ein Paar fehlt
+func evalAlarmExpression(expr string, latest map[string]float64) (fire bool, ok bool, err error) {
+	e, err := compileAlarmExpression(expr)
+	if err != nil {
+		return false, false, err
+	}
+
+	params := expressionParams(latest)
+	for _, v := range e.Vars() {
+		if _, found := params[v]; !found {
+			return false, false, nil
+		}
+	}
+
+	result, err := e.Evaluate(params)
+	if err != nil {
+		return false, false, err
+	}
+	b, isBool := result.(bool)
+	if !isBool {
+		return false, false, fmt.Errorf("expression %q does not evaluate to true/false", expr)
+	}
+	return b, true, nil
+}
+
+// Variablen aus Kursen: EUR/CHF als EURCHF, Kehrwert als CHFEUR
+func expressionParams(latest map[string]float64) map[string]interface{} {
+	params := make(map[string]interface{}, 2*len(latest))
+	for key, rate := range latest {
+		from, to, found := strings.Cut(key, "/")
+		if !found || rate == 0 {
+			continue
+		}
+		if _, exists := params[to+from]; !exists {
+			params[to+from] = 1 / rate
+		}
+	}
+	// Direkte Kurse haben Vorrang vor Kehrwerten
+	for key, rate := range latest {
+		params[strings.ReplaceAll(key, "/", "")] = rate
+	}
+	return params
+}
+
+func foldNumbers(name string, args []interface{}, better func(a, b float64) bool) (interface{}, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("%s expects at least 1 argument", name)
+	}
+	var out float64
+	for i, arg := range args {
+		v, ok := arg.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s expects numbers", name)
+		}
+		if i == 0 || better(out, v) {
+			out = v
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Failover über mehrere Quellen
+
+const (
+	maxProviderFailures    = 3
+	defaultProviderCooloff = 10 * time.Minute
+)
+
+// Zustand einer Quelle
+type providerHealth struct {
+	failures  int
+	lastError error
+	skipUntil time.Time
+}
+
+var (
+	healthMu       sync.Mutex
+	providerStates = map[string]*providerHealth{}
+
+	activeSourceMu sync.RWMutex
+	activeSource   string
+)
+
+// Quellen in Reihenfolge, gestörte werden übersprungen
+type providerChain struct {
+	providers []RateProvider
+	cooloff   time.Duration
+}
+
+// Kette aus Config (providers, sonst provider)
+func newProviderChain(cfg Config) (*providerChain, error) {
+	names := cfg.Providers
+	if len(names) == 0 {
+		names = []string{cfg.Provider}
+	}
+
+	chain := &providerChain{cooloff: defaultProviderCooloff}
+	if cfg.ProviderCooloffMinutes > 0 {
+		chain.cooloff = time.Duration(cfg.ProviderCooloffMinutes) * time.Minute
+	}
+	for _, name := range names {
+		p, err := providerByName(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		chain.providers = append(chain.providers, p)
+	}
+	return chain, nil
+}
+
+func (c *providerChain) Name() string {
+	names := make([]string, 0, len(c.providers))
+	for _, p := range c.providers {
+		names = append(names, p.Name())
+	}
+	return strings.Join(names, ",")
+}
+
+func (c *providerChain) Latest(base string) (*RateTable, error) {
+	var rt *RateTable
+	err := c.try(func(p RateProvider) error {
+		var err error
+		rt, err = p.Latest(base)
+		return err
+	})
+	return rt, err
+}
+
+func (c *providerChain) Currencies() ([]string, error) {
+	var codes []string
+	err := c.try(func(p RateProvider) error {
+		var err error
+		codes, err = p.Currencies()
+		return err
+	})
+	return codes, err
+}
+
+// Erste gesunde Quelle, die antwortet; sind alle gestört, werden alle versucht
+func (c *providerChain) try(call func(p RateProvider) error) error {
+	now := time.Now()
+
+	candidates := make([]RateProvider, 0, len(c.providers))
+	for _, p := range c.providers {
+		if providerHealthy(p.Name(), now) {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = c.providers
+	}
+
+	var errs []error
+	for _, p := range candidates {
+		err := call(p)
+		if err == nil {
+			markProviderSuccess(p.Name())
+			setActiveSource(p.Name())
+			return nil
+		}
+		markProviderFailure(p.Name(), err, c.cooloff)
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+	}
+	return errors.Join(errs...)
+}
+
+// Health-Tracking
+
+func providerHealthy(name string, now time.Time) bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := providerStates[name]
+	return !ok || !now.Before(h.skipUntil)
+}
+
+func markProviderSuccess(name string) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	delete(providerStates, name)
+}
+
+func markProviderFailure(name string, err error, cooloff time.Duration) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := providerStates[name]
+	if !ok {
+		h = &providerHealth{}
+		providerStates[name] = h
+	}
+	h.failures++
+	h.lastError = err
+	if h.failures >= maxProviderFailures {
+		h.skipUntil = time.Now().Add(cooloff)
+	}
+}
+
+func setActiveSource(name string) {
+	activeSourceMu.Lock()
+	defer activeSourceMu.Unlock()
+	activeSource = name
+}
+
+func getActiveSource() string {
+	activeSourceMu.RLock()
+	defer activeSourceMu.RUnlock()
+	return activeSource
+}
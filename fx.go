@@ -1,10 +1,8 @@
 package main
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"strings"
 	"time"
 
@@ -18,23 +16,62 @@ func init() {
 
 // Kurs-Update
 
-// Config laden, Kurse aktualisieren
+// Config laden, fällige Paare aktualisieren
 func updateLoop() {
 	for {
 		if err := loadConfig(); err != nil {
 			fmt.Println("loadConfig:", err)
 		}
-		if err := refreshRatesAndTooltip(); err != nil {
-			fmt.Println("refreshRates:", err)
+
+		configMu.RLock()
+		cfg := currentConfig
+		configMu.RUnlock()
+
+		now := time.Now()
+		due := duePairs(cfg, now)
+		open := marketOpen(cfg, now)
+		if !open {
+			// Markt geschlossen: nur Paare ohne jeden Kurs abrufen
+			states := getPairStates()
+			for key := range due {
+				if _, ok := states[key]; ok {
+					delete(due, key)
+				}
+			}
+		}
+
+		if len(due) > 0 || len(cfg.Pairs) == 0 {
+			if err := refreshRates(due); err != nil {
+				fmt.Println("refreshRates:", err)
+			}
+		}
+		if !open && len(cfg.Pairs) > 0 {
+			updateTooltip(cfg, getPairStates(), now)
 		}
 
 		setNextAutoUpdate()
-		time.Sleep(defaultInterval)
+		wait := refreshInterval(cfg)
+		if next := getNextAutoUpdate(); !next.IsZero() {
+			wait = max(time.Until(next), time.Second)
+		}
+		if change := nextMarketChange(cfg, now); !change.IsZero() {
+			wait = min(wait, max(time.Until(change), time.Second))
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-rescheduleChan:
+		}
 	}
 }
 
-// Kurse holen, Tooltip aktualisieren, Alarme prüfen
+// Alle Paare aktualisieren
 func refreshRatesAndTooltip() error {
+	return refreshRates(nil)
+}
+
+// Kurse holen (nil = alle Paare), Tooltip aktualisieren, Alarme prüfen
+func refreshRates(due map[string]bool) error {
 	configMu.RLock()
 	cfg := currentConfig
 	configMu.RUnlock()
@@ -44,122 +81,144 @@ func refreshRatesAndTooltip() error {
 		return nil
 	}
 
-	bases := map[string]struct{}{}
-	for _, p := range cfg.Pairs {
-		bases[strings.ToUpper(p.From)] = struct{}{}
+	// Nur fällige Paare abrufen
+	sub := cfg
+	if due != nil {
+		sub.Pairs = nil
+		for _, p := range cfg.Pairs {
+			if due[pairKey(p.From, p.To)] {
+				sub.Pairs = append(sub.Pairs, p)
+			}
+		}
+		if cfg.Consensus != nil {
+			cc := *cfg.Consensus
+			cc.Pairs = nil
+			for _, pair := range cfg.Consensus.Pairs {
+				if due[normalizeAlarmPair(pair)] {
+					cc.Pairs = append(cc.Pairs, pair)
+				}
+			}
+			sub.Consensus = &cc
+		}
 	}
 
-	tmpRates := map[string]float64{}
+	provider, err := newRateProvider(cfg)
+	if err != nil {
+		return err
+	}
 
-	for base := range bases {
-		rr, err := fetchRates(base)
-		if err != nil {
-			return err
-		}
+	now := time.Now()
+	fresh := map[string]pairState{}
+	pairErrs := map[string]error{}
+	var errs []error
 
-		for _, p := range cfg.Pairs {
-			if strings.ToUpper(p.From) != base {
+	// Fehler je Basis isolieren
+	for base, pairs := range fetchPlan(sub) {
+		rr, err := latestScheduled(provider, base, now)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", base, err)
+			errs = append(errs, err)
+			for _, p := range pairs {
+				pairErrs[pairKey(p.From, p.To)] = err
+			}
+			// Ohne Cache-Tabelle bleibt der letzte Stand (veraltet)
+			if rr == nil {
 				continue
 			}
-			key := pairKey(p.From, p.To)
-			if rate, ok := rr.Rates[strings.ToUpper(p.To)]; ok {
-				tmpRates[key] = rate
+		}
+
+		for _, p := range pairs {
+			if rate, ok := crossRate(rr, p.From, p.To); ok {
+				fresh[pairKey(p.From, p.To)] = pairState{
+					Rate:    rate,
+					Updated: rr.Fetched,
+					AsOf:    rr.AsOf,
+					Cached:  rr.FromDisk,
+				}
 			}
 		}
 	}
 
-	ratesMu.Lock()
-	rates = tmpRates
-	ratesMu.Unlock()
+	// Konsens-Paare überschreiben Einzelkurse
+	for key, rate := range consensusRates(sub) {
+		fresh[key] = pairState{Rate: rate, Updated: now}
+		delete(pairErrs, key)
+	}
 
-	var lines []string
-	for _, p := range cfg.Pairs {
-		key := pairKey(p.From, p.To)
-		if rate, ok := tmpRates[key]; ok {
-			lines = append(lines, fmt.Sprintf("%s: %.4f", key, rate))
+	states := mergePairStates(cfg, fresh, pairErrs, due)
+	markPairsRefreshed(scheduledPairs(sub), now)
+
+	// Alarme nur auf frischen Kursen, Cache-Werte nur bis zum Höchstalter
+	maxAge := alarmMaxAge(cfg)
+	tmpRates := map[string]float64{}
+	var expired []string
+	for key, st := range fresh {
+		if st.Cached && now.Sub(st.Updated) > maxAge {
+			expired = append(expired, key)
+			continue
 		}
+		tmpRates[key] = st.Rate
 	}
 
-	if len(lines) == 0 {
-		systray.SetTooltip("No rates available")
+	ratesMu.Lock()
+	if due == nil {
+		rates = tmpRates
 	} else {
-		systray.SetTooltip(strings.Join(lines, "\n"))
+		for key, rate := range tmpRates {
+			rates[key] = rate
+		}
+		for _, key := range expired {
+			delete(rates, key)
+		}
 	}
-
-	checkAlarms(cfg, tmpRates)
-
-	return nil
-}
-
-// API Call
-
-func fetchRates(base string) (*rateResponse, error) {
-	url := fmt.Sprintf("https://open.er-api.com/v6/latest/%s", strings.ToUpper(base))
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
+	// Ausdrücke auch über Paare früherer Durchläufe, ohne veraltete
+	allRates := make(map[string]float64, len(rates))
+	for key, rate := range rates {
+		if st, ok := states[key]; ok && st.stale() {
+			continue
+		}
+		allRates[key] = rate
 	}
-	defer resp.Body.Close()
+	ratesMu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("fx api: status %d: %s", resp.StatusCode, string(body))
-	}
+	updateTooltip(cfg, states, now)
 
-	var rr rateResponse
-	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
-		return nil, err
+	if err := saveRateCache(); err != nil {
+		fmt.Println("saveRateCache:", err)
 	}
-	if rr.Result != "success" {
-		return nil, fmt.Errorf("fx api returned result=%s", rr.Result)
+	if err := recordHistory(cfg, fresh, getActiveSource()); err != nil {
+		fmt.Println("recordHistory:", err)
 	}
 
-	return &rr, nil
-}
-
-// Alarm
+	checkAlarms(cfg, tmpRates, allRates)
 
-func checkAlarms(cfg Config, latest map[string]float64) {
-	now := time.Now()
-
-	for _, a := range cfg.Alarms {
-		key := normalizeAlarmPair(a.Pair)
-		rate, ok := latest[key]
-		if !ok {
-			continue
-		}
-
-		dir := strings.ToLower(strings.TrimSpace(a.Direction))
-		trigKey := fmt.Sprintf("%s:%.4f:%s", key, a.Target, dir)
+	return errors.Join(errs...)
+}
 
-		shouldFire := false
-		switch dir {
-		case "above":
-			if rate >= a.Target {
-				shouldFire = true
-			}
-		case "below":
-			if rate <= a.Target {
-				shouldFire = true
-			}
-		default:
-			continue
+// Tooltip aus Kursstatus, veraltete Kurse mit Alter
+func updateTooltip(cfg Config, states map[string]pairState, now time.Time) {
+	var lines []string
+	for _, p := range cfg.Pairs {
+		key := pairKey(p.From, p.To)
+		st, ok := states[key]
+		switch {
+		case !ok:
+		case st.stale():
+			lines = append(lines, fmt.Sprintf("%s: %.4f (stale %s)", key, st.Rate, formatAge(now.Sub(st.Updated))))
+		case st.Err == nil && st.Cached:
+			lines = append(lines, fmt.Sprintf("%s: %.4f (cached %s)", key, st.Rate, formatAge(now.Sub(st.Updated))))
+		case st.Err == nil:
+			lines = append(lines, fmt.Sprintf("%s: %.4f", key, st.Rate))
 		}
+	}
 
-		triggeredMu.Lock()
-		lastTime, exists := lastTriggered[trigKey]
-		canTrigger := !exists || now.Sub(lastTime) >= alarmCooldown
-
-		if shouldFire && canTrigger {
-			lastTriggered[trigKey] = now
-			triggeredMu.Unlock()
-
-			msg := fmt.Sprintf("%s is now %.4f (target %.4f %s)", key, rate, a.Target, dir)
-			_ = beeep.Notify("FX Alarm", msg, "")
-		} else {
-			triggeredMu.Unlock()
-		}
+	if len(lines) == 0 {
+		lines = append(lines, "No rates available")
+	}
+	if !marketOpen(cfg, now) {
+		lines = append([]string{"Market closed"}, lines...)
 	}
+	systray.SetTooltip(strings.Join(lines, "\n"))
 }
 
 // Helper
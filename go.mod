@@ -6,6 +6,7 @@ require (
 	github.com/gen2brain/beeep v0.11.1
 	github.com/getlantern/systray v1.2.2
 	github.com/lxn/walk v0.0.0-20210112085537-c389da54e794
+	gopkg.in/Knetic/govaluate.v3 v3.0.0
 )
 
 require (
@@ -28,5 +29,4 @@ require (
 	github.com/sergeymakinen/go-ico v1.0.0 // indirect
 	github.com/tadvi/systray v0.0.0-20190226123456-11a2b8fa57af // indirect
 	golang.org/x/sys v0.38.0 // indirect
-	gopkg.in/Knetic/govaluate.v3 v3.0.0 // indirect
 )
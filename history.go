@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kurshistorie (fxtray.history.jsonl, eine Zeile je Kurs)
+
+const (
+	defaultHistoryRawDays   = 7
+	defaultHistoryDailyDays = 730
+	historyCompactEvery     = 24 * time.Hour
+)
+
+// Kurs zu einem Zeitpunkt der Quelle; Daily = Tagesschluss nach Verdichtung
+type historyPoint struct {
+	Pair   string    `json:"pair"`
+	Time   time.Time `json:"time"`
+	Rate   float64   `json:"rate"`
+	Source string    `json:"source,omitempty"`
+	Daily  bool      `json:"daily,omitempty"`
+}
+
+// Punkte je Paar, aufsteigend nach Zeit
+var (
+	historyMu sync.Mutex
+	history   = map[string][]historyPoint{}
+)
+
+func historyPath() string {
+	return configSiblingPath(".history.jsonl")
+}
+
+// Historie laden
+func loadHistory() error {
+	f, err := os.Open(historyPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	loaded := map[string][]historyPoint{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var pt historyPoint
+		if err := json.Unmarshal(sc.Bytes(), &pt); err != nil {
+			continue
+		}
+		loaded[pt.Pair] = append(loaded[pt.Pair], pt)
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	for pair := range loaded {
+		pts := loaded[pair]
+		sort.SliceStable(pts, func(i, j int) bool { return pts[i].Time.Before(pts[j].Time) })
+	}
+
+	historyMu.Lock()
+	history = loaded
+	historyMu.Unlock()
+	return nil
+}
+
+// Neue Kurse anhängen; gleicher Zeitpunkt der Quelle wird nur einmal gespeichert
+func recordHistory(cfg Config, fresh map[string]pairState, source string) error {
+	if cfg.History.Disabled {
+		return nil
+	}
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	var added []historyPoint
+	for pair, st := range fresh {
+		t := st.AsOf
+		if t.IsZero() {
+			t = st.Updated
+		}
+		pts := history[pair]
+		if n := len(pts); n > 0 && !t.After(pts[n-1].Time) {
+			continue
+		}
+		pt := historyPoint{Pair: pair, Time: t, Rate: st.Rate, Source: source}
+		history[pair] = append(pts, pt)
+		added = append(added, pt)
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	f, err := os.OpenFile(historyPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	enc := json.NewEncoder(f)
+	for _, pt := range added {
+		if err := enc.Encode(pt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Punkte eines Paares ab since
+func historySince(pair string, since time.Time) []historyPoint {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	pts := history[pair]
+	i := sort.Search(len(pts), func(i int) bool { return !pts[i].Time.Before(since) })
+	return append([]historyPoint(nil), pts[i:]...)
+}
+
+// Letzter Punkt eines Paares bis einschliesslich t
+func historyAt(pair string, t time.Time) (historyPoint, bool) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	pts := history[pair]
+	i := sort.Search(len(pts), func(i int) bool { return pts[i].Time.After(t) })
+	if i == 0 {
+		return historyPoint{}, false
+	}
+	return pts[i-1], true
+}
+
+// Aufbewahrung: Rohdaten für raw_days, danach Tagesschluss bis daily_days
+func compactHistory(cfg Config, now time.Time) error {
+	rawDays := cfg.History.RawDays
+	if rawDays <= 0 {
+		rawDays = defaultHistoryRawDays
+	}
+	dailyDays := cfg.History.DailyDays
+	if dailyDays <= 0 {
+		dailyDays = defaultHistoryDailyDays
+	}
+	rawCutoff := now.AddDate(0, 0, -rawDays)
+	dailyCutoff := now.AddDate(0, 0, -dailyDays)
+
+	historyMu.Lock()
+	defer historyMu.Unlock()
+
+	compacted := map[string][]historyPoint{}
+	for pair, pts := range history {
+		var out []historyPoint
+		for i, pt := range pts {
+			switch {
+			case pt.Time.Before(dailyCutoff):
+				continue
+			case !pt.Time.Before(rawCutoff):
+				out = append(out, pt)
+			default:
+				// Letzter Punkt einer Handelssitzung (bis 17:00 New York) bleibt als Tagesschluss
+				if i+1 < len(pts) && previousClose(pts[i+1].Time).Equal(previousClose(pt.Time)) {
+					continue
+				}
+				pt.Daily = true
+				out = append(out, pt)
+			}
+		}
+		if len(out) > 0 {
+			compacted[pair] = out
+		}
+	}
+
+	tmp := historyPath() + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, pts := range compacted {
+		for _, pt := range pts {
+			if err := enc.Encode(pt); err != nil {
+				f.Close()
+				return err
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, historyPath()); err != nil {
+		return fmt.Errorf("replace history: %w", err)
+	}
+
+	history = compacted
+	return nil
+}
+
+// Verdichtung beim Start und danach täglich
+func historyLoop() {
+	for {
+		configMu.RLock()
+		cfg := currentConfig
+		configMu.RUnlock()
+
+		if !cfg.History.Disabled {
+			if err := compactHistory(cfg, time.Now()); err != nil {
+				fmt.Println("compactHistory:", err)
+			}
+		}
+		time.Sleep(historyCompactEvery)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompactHistoryKeepsSessionClose(t *testing.T) {
+	oldPath := configPath
+	configPath = filepath.Join(t.TempDir(), "fxtray.json")
+	t.Cleanup(func() { configPath = oldPath })
+
+	ny := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, newYork)
+	}
+	// Dienstag und Mittwoch, je eine Sitzung bis 17:00 New York
+	pts := []historyPoint{
+		{Pair: "EUR/USD", Time: ny(3, 9, 0), Rate: 1.01},
+		{Pair: "EUR/USD", Time: ny(3, 16, 59), Rate: 1.02}, // Schluss Dienstag
+		{Pair: "EUR/USD", Time: ny(3, 17, 0), Rate: 1.03},
+		{Pair: "EUR/USD", Time: ny(3, 22, 30), Rate: 1.04}, // nach UTC-Mitternacht, gleiche Sitzung
+		{Pair: "EUR/USD", Time: ny(4, 16, 0), Rate: 1.05},  // Schluss Mittwoch
+	}
+
+	historyMu.Lock()
+	oldHistory := history
+	history = map[string][]historyPoint{"EUR/USD": pts}
+	historyMu.Unlock()
+	t.Cleanup(func() {
+		historyMu.Lock()
+		history = oldHistory
+		historyMu.Unlock()
+	})
+
+	if err := compactHistory(Config{}, ny(20, 12, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	historyMu.Lock()
+	got := history["EUR/USD"]
+	historyMu.Unlock()
+
+	want := []float64{1.02, 1.05}
+	if len(got) != len(want) {
+		t.Fatalf("kept %d points, want %d: %+v", len(got), len(want), got)
+	}
+	for i, pt := range got {
+		if pt.Rate != want[i] || !pt.Daily {
+			t.Errorf("point %d = %.2f (daily %v), want %.2f daily", i, pt.Rate, pt.Daily, want[i])
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// HTTP-Client mit Timeout, Retry und Proxy
+
+const (
+	defaultHTTPTimeout = 20 * time.Second
+	defaultBackoff     = 500 * time.Millisecond
+	defaultMaxBackoff  = 30 * time.Second
+	defaultUserAgent   = "FX Tray App"
+	defaultHTTPRetries = 2
+)
+
+type fxHTTPClient struct {
+	client     *http.Client
+	retries    int
+	backoff    time.Duration
+	maxBackoff time.Duration
+	userAgent  string
+
+	condMu sync.Mutex
+	cond   map[string]conditionalEntry
+}
+
+// Validatoren und letzte Antwort je URL für bedingte Anfragen
+type conditionalEntry struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+// Client wird je Einstellung einmal gebaut (Verbindungen wiederverwenden)
+var (
+	httpClientMu     sync.Mutex
+	httpClientKey    httpConfigKey
+	httpClientCached *fxHTTPClient
+)
+
+// Vergleichbare Einstellung: Retries als Wert statt Zeiger
+type httpConfigKey struct {
+	cfg     HTTPConfig
+	retries int
+}
+
+func keyForHTTPConfig(hc HTTPConfig) httpConfigKey {
+	key := httpConfigKey{cfg: hc, retries: httpRetries(hc)}
+	key.cfg.Retries = nil
+	return key
+}
+
+// Wiederholungen: fehlt = Standard, 0 = keine
+func httpRetries(hc HTTPConfig) int {
+	if hc.Retries == nil {
+		return defaultHTTPRetries
+	}
+	return max(*hc.Retries, 0)
+}
+
+func httpClientFor(hc HTTPConfig) (*fxHTTPClient, error) {
+	httpClientMu.Lock()
+	defer httpClientMu.Unlock()
+
+	key := keyForHTTPConfig(hc)
+	if httpClientCached != nil && httpClientKey == key {
+		return httpClientCached, nil
+	}
+	c, err := newHTTPClient(hc)
+	if err != nil {
+		return nil, err
+	}
+	httpClientKey = key
+	httpClientCached = c
+	return c, nil
+}
+
+func newHTTPClient(hc HTTPConfig) (*fxHTTPClient, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if hc.ProxyURL != "" {
+		proxy, err := url.Parse(hc.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("http: invalid proxy_url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	if hc.CABundle != "" {
+		pem, err := os.ReadFile(hc.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("http: ca_bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http: ca_bundle %s contains no certificates", hc.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	c := &fxHTTPClient{
+		client:     &http.Client{Timeout: defaultHTTPTimeout, Transport: transport},
+		retries:    httpRetries(hc),
+		backoff:    defaultBackoff,
+		maxBackoff: defaultMaxBackoff,
+		userAgent:  defaultUserAgent,
+		cond:       map[string]conditionalEntry{},
+	}
+	if hc.TimeoutSeconds > 0 {
+		c.client.Timeout = time.Duration(hc.TimeoutSeconds) * time.Second
+	}
+	if hc.BackoffMillis > 0 {
+		c.backoff = time.Duration(hc.BackoffMillis) * time.Millisecond
+	}
+	if hc.MaxBackoffMillis > 0 {
+		c.maxBackoff = time.Duration(hc.MaxBackoffMillis) * time.Millisecond
+	}
+	if hc.UserAgent != "" {
+		c.userAgent = hc.UserAgent
+	}
+	return c, nil
+}
+
+// GET mit Retry bei Netzwerkfehlern, 429 und 5xx
+func (c *fxHTTPClient) Get(rawURL string, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodGet, rawURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		for k, vs := range header {
+			req.Header[k] = vs
+		}
+		req.Header.Set("User-Agent", c.userAgent)
+
+		resp, err := c.client.Do(req)
+		if attempt >= c.retries || !shouldRetry(resp, err) {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		time.Sleep(c.backoffFor(attempt))
+	}
+}
+
+// Einzelne Anfrage ohne Retry (Wiederholung übernimmt der Aufrufer)
+func (c *fxHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	return c.client.Do(req)
+}
+
+// GET mit ETag/If-Modified-Since; bei 304 wird die letzte Antwort geliefert
+func (c *fxHTTPClient) GetConditional(rawURL string) ([]byte, error) {
+	c.condMu.Lock()
+	prev, cached := c.cond[rawURL]
+	c.condMu.Unlock()
+
+	header := http.Header{}
+	if cached {
+		if prev.etag != "" {
+			header.Set("If-None-Match", prev.etag)
+		}
+		if prev.lastModified != "" {
+			header.Set("If-Modified-Since", prev.lastModified)
+		}
+	}
+
+	resp, err := c.Get(rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		return prev.body, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d: %s", resp.StatusCode, string(body))
+	}
+
+	entry := conditionalEntry{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		body:         body,
+	}
+	c.condMu.Lock()
+	if entry.etag != "" || entry.lastModified != "" {
+		c.cond[rawURL] = entry
+	} else {
+		delete(c.cond, rawURL)
+	}
+	c.condMu.Unlock()
+
+	return body, nil
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// Exponentielles Backoff mit Jitter (50–100 % des Werts)
+func (c *fxHTTPClient) backoffFor(attempt int) time.Duration {
+	d := c.backoff << attempt
+	if d <= 0 || d > c.maxBackoff {
+		d = c.maxBackoff
+	}
+	half := int64(d / 2)
+	return time.Duration(half + rand.Int64N(half+1))
+}
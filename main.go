@@ -30,6 +30,7 @@ var (
 	nextAutoUpdate time.Time
 	rates          = map[string]float64{}
 
+	// Cooldown für Benachrichtigungen ausserhalb der Alarme (z.B. Abweichungen)
 	triggeredMu   sync.Mutex
 	lastTriggered = map[string]time.Time{}
 	alarmCooldown = 5 * time.Minute
@@ -51,6 +52,22 @@ func main() {
 		}
 	}
 
+	if err := loadRateCache(); err != nil {
+		fmt.Println("cannot load rate cache:", err)
+	}
+	if err := loadHistory(); err != nil {
+		fmt.Println("cannot load history:", err)
+	}
+	if err := loadAlarmStates(); err != nil {
+		fmt.Println("cannot load alarm state:", err)
+	}
+	if err := loadAlarmLog(); err != nil {
+		fmt.Println("cannot load alarm log:", err)
+	}
+	if err := loadHeldNotifications(); err != nil {
+		fmt.Println("cannot load notification queue:", err)
+	}
+
 	go func() {
 		for range openSettingsChan {
 			go func() {
@@ -70,10 +87,26 @@ func onReady() {
 	systray.SetTitle("FX Tray")
 	systray.SetTooltip("Loading FX rates...")
 
+	// Letzte Kurse aus dem Cache anzeigen
+	configMu.RLock()
+	cfg := currentConfig
+	configMu.RUnlock()
+	if states := seedPairStatesFromCache(cfg); len(states) > 0 {
+		updateTooltip(cfg, states, time.Now())
+	}
+
 	mSettings := systray.AddMenuItem("Settings…", "Open settings window")
 	mRefresh := systray.AddMenuItem("Refresh Rates", "Manually refresh FX rates")
 	systray.AddSeparator()
 	mLastUpdated := systray.AddMenuItem("Last Updated: N/A", "Last FX rates update time")
+	mSource := systray.AddMenuItem("Source: N/A", "Rate provider used for the last update")
+	mSource.Disable()
+	mStatus := systray.AddMenuItem("Status", "Per-pair update status")
+	statusMenu := newDynamicMenu(mStatus)
+	mAlarms := systray.AddMenuItem("Alarms", "Enable, disable or snooze alarms")
+	alarmsMenu := newAlarmMenu(mAlarms)
+	mRecent := systray.AddMenuItem("Recent Alarms", "Recently fired alarms")
+	recentMenu := newDynamicMenu(mRecent)
 	systray.AddSeparator()
 	mQuit := systray.AddMenuItem("Quit", "Quit application")
 
@@ -96,8 +129,14 @@ func onReady() {
 	// Refresh-Menü
 	go func() {
 		for {
+			configMu.RLock()
+			cfg := currentConfig
+			configMu.RUnlock()
+
 			next := getNextAutoUpdate()
-			if next.IsZero() {
+			if !marketOpen(cfg, time.Now()) {
+				mRefresh.SetTitle("Refresh Rates (market closed)")
+			} else if next.IsZero() {
 				mRefresh.SetTitle("Refresh Rates")
 			} else {
 				remaining := time.Until(next)
@@ -128,21 +167,35 @@ func onReady() {
 				fmt.Println("manual refresh:", err)
 			}
 			updateLastUpdated(mLastUpdated)
-			setNextAutoUpdate()
+			updateSource(mSource)
+			updateStatusMenu(statusMenu)
+			updateAlarmsMenu(alarmsMenu)
+			updateRecentMenu(recentMenu)
+			requestReschedule()
 		}
 	}()
 
-	// Anzeige "Last Updated"
+	// Anzeige "Last Updated", Quelle, Status und Alarme
 	go func() {
 		updateLastUpdated(mLastUpdated)
+		updateSource(mSource)
+		updateStatusMenu(statusMenu)
+		updateAlarmsMenu(alarmsMenu)
+		updateRecentMenu(recentMenu)
 
 		for {
 			time.Sleep(30 * time.Second)
 			updateLastUpdated(mLastUpdated)
+			updateSource(mSource)
+			updateStatusMenu(statusMenu)
+			updateAlarmsMenu(alarmsMenu)
+			updateRecentMenu(recentMenu)
 		}
 	}()
 
 	go updateLoop()
+	go historyLoop()
+	go quietLoop()
 }
 
 func onExit() {
@@ -151,9 +204,18 @@ func onExit() {
 // Auto-Update
 
 func setNextAutoUpdate() {
+	configMu.RLock()
+	cfg := currentConfig
+	configMu.RUnlock()
+
+	next := nextDueTime(cfg)
+	if now := time.Now(); !marketOpen(cfg, now) {
+		next = nextMarketOpen(cfg, now)
+	}
+
 	nextUpdateMu.Lock()
 	defer nextUpdateMu.Unlock()
-	nextAutoUpdate = time.Now().Add(defaultInterval)
+	nextAutoUpdate = next
 }
 
 func getNextAutoUpdate() time.Time {
@@ -162,7 +224,54 @@ func getNextAutoUpdate() time.Time {
 	return nextAutoUpdate
 }
 
+// Stand der Kurse laut Quelle, nicht lokale Uhrzeit
 func updateLastUpdated(m *systray.MenuItem) {
-	now := time.Now().Format("15:04:05")
-	m.SetTitle("Last Updated: " + now)
+	asOf := latestAsOf(getPairStates())
+	if asOf.IsZero() {
+		m.SetTitle("Last Updated: N/A")
+		return
+	}
+	asOf = asOf.Local()
+	y, mo, d := asOf.Date()
+	ny, nmo, nd := time.Now().Date()
+	if y == ny && mo == nmo && d == nd {
+		m.SetTitle("Last Updated: " + asOf.Format("15:04:05"))
+		return
+	}
+	m.SetTitle("Last Updated: " + asOf.Format("02.01.2006 15:04"))
+}
+
+func updateSource(m *systray.MenuItem) {
+	source := getActiveSource()
+	if source == "" {
+		source = "N/A"
+	}
+	m.SetTitle("Source: " + source)
+}
+
+func updateStatusMenu(d *dynamicMenu) {
+	configMu.RLock()
+	cfg := currentConfig
+	configMu.RUnlock()
+
+	lines := statusLines(cfg, getPairStates(), time.Now())
+	if len(lines) == 0 {
+		lines = []string{"No currency pairs configured"}
+	}
+	d.set(lines)
+}
+
+func updateAlarmsMenu(m *alarmMenu) {
+	configMu.RLock()
+	cfg := currentConfig
+	configMu.RUnlock()
+	m.update(cfg)
+}
+
+func updateRecentMenu(d *dynamicMenu) {
+	lines := recentAlarmLines(10)
+	if len(lines) == 0 {
+		lines = []string{"No alarms fired yet"}
+	}
+	d.set(lines)
 }
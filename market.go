@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+// FX-Handelszeiten: Freitag 17:00 New York bis Sonntag 17:00 New York (Eröffnung Sydney)
+
+const marketCloseHour = 17
+
+var newYork = mustLoadLocation("America/New_York")
+
+func mustLoadLocation(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		fmt.Println("load location:", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// Markt offen (Wochenende und Feiertage geschlossen)
+func marketOpen(cfg Config, t time.Time) bool {
+	if cfg.Market.AlwaysOpen {
+		return true
+	}
+	ny := t.In(newYork)
+
+	switch ny.Weekday() {
+	case time.Saturday:
+		return false
+	case time.Friday:
+		if ny.Hour() >= marketCloseHour {
+			return false
+		}
+	case time.Sunday:
+		if ny.Hour() < marketCloseHour {
+			return false
+		}
+	}
+
+	day := ny.Format("2006-01-02")
+	for _, h := range cfg.Market.Holidays {
+		if h == day {
+			return false
+		}
+	}
+	return true
+}
+
+// Nächster Wechsel offen/geschlossen (Grenzen liegen auf vollen Stunden in New York)
+func nextMarketChange(cfg Config, t time.Time) time.Time {
+	if cfg.Market.AlwaysOpen {
+		return time.Time{}
+	}
+	open := marketOpen(cfg, t)
+	h := t.In(newYork).Truncate(time.Hour)
+	for i := 0; i < 24*14; i++ {
+		h = h.Add(time.Hour)
+		if marketOpen(cfg, h) != open {
+			return h
+		}
+	}
+	return time.Time{}
+}
+
+// Nächste Öffnung ab t (t selbst, wenn offen)
+func nextMarketOpen(cfg Config, t time.Time) time.Time {
+	if marketOpen(cfg, t) {
+		return t
+	}
+	return nextMarketChange(cfg, t)
+}
+
+// Beginn der nächsten Handelssitzung (Tageswechsel 17:00 New York, danach nächste Öffnung)
+func nextSessionStart(cfg Config, t time.Time) time.Time {
+	ny := t.In(newYork)
+	start := time.Date(ny.Year(), ny.Month(), ny.Day(), marketCloseHour, 0, 0, 0, newYork)
+	if !start.After(t) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return nextMarketOpen(cfg, start)
+}
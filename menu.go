@@ -0,0 +1,38 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/getlantern/systray"
+)
+
+// Untermenü mit variabler Anzahl Einträge (systray kann Einträge nur ausblenden)
+type dynamicMenu struct {
+	mu     sync.Mutex
+	parent *systray.MenuItem
+	items  []*systray.MenuItem
+}
+
+func newDynamicMenu(parent *systray.MenuItem) *dynamicMenu {
+	return &dynamicMenu{parent: parent}
+}
+
+// Einträge setzen, überzählige ausblenden
+func (d *dynamicMenu) set(titles []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, title := range titles {
+		if i < len(d.items) {
+			d.items[i].SetTitle(title)
+			d.items[i].Show()
+			continue
+		}
+		item := d.parent.AddSubMenuItem(title, "")
+		item.Disable()
+		d.items = append(d.items, item)
+	}
+	for i := len(titles); i < len(d.items); i++ {
+		d.items[i].Hide()
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// Meldungstexte aus Vorlagen, Zahlen im Format der Locale (z.B. 0,9312 für de-CH)
+
+// Art der Meldung
+const (
+	kindLevel      = "level"
+	kindChange     = "change"
+	kindCross      = "cross"
+	kindExpression = "expression"
+)
+
+// Felder in Titel- und Text-Vorlagen, z.B. {{.Pair}} oder {{num .Rate 4}}
+type alarmMessage struct {
+	Kind          string
+	Rule          string
+	Pair          string
+	Rate          float64
+	Target        float64
+	Direction     string
+	Change        float64
+	Reference     string
+	ReferenceRate float64
+	WindowHours   float64
+	Crossed       string
+	Expression    string
+	Time          time.Time
+}
+
+type messageTemplates struct {
+	title, body string
+}
+
+// Eingebaute Vorlagen je Sprache
+var builtinMessages = map[string]messageTemplates{
+	"en": {
+		title: "FX Alarm",
+		body: `
+{{- if eq .Kind "expression"}}Condition met: {{.Expression}}
+{{- else if eq .Kind "cross"}}{{.Pair}} crossed {{.Crossed}} {{num .Target 4}} (now {{num .Rate 4}})
+{{- else if eq .Kind "change"}}{{.Pair}} is now {{num .Rate 4}} ({{signed .Change 2}}% vs
+{{- if eq .Reference "created"}} creation
+{{- else if eq .Reference "window"}} {{num .WindowHours -1}}h ago
+{{- else}} prev. close{{end}} {{num .ReferenceRate 4}}, target {{num .Target 2}}%)
+{{- else}}{{.Pair}} is now {{num .Rate 4}} (target {{num .Target 4}} {{.Direction}})
+{{- end}}`,
+	},
+	"de": {
+		title: "Devisen-Alarm",
+		body: `
+{{- if eq .Kind "expression"}}Bedingung erfüllt: {{.Expression}}
+{{- else if eq .Kind "cross"}}{{.Pair}} hat {{num .Target 4}} nach
+{{- if eq .Crossed "above"}} oben{{else}} unten{{end}} gekreuzt (jetzt {{num .Rate 4}})
+{{- else if eq .Kind "change"}}{{.Pair}} steht bei {{num .Rate 4}} ({{signed .Change 2}} % ggü.
+{{- if eq .Reference "created"}} Erstellung
+{{- else if eq .Reference "window"}} vor {{num .WindowHours -1}} h
+{{- else}} Vortagesschluss{{end}} {{num .ReferenceRate 4}}, Ziel {{num .Target 2}} %)
+{{- else}}{{.Pair}} steht bei {{num .Rate 4}} (Ziel
+{{- if eq .Direction "above"}} über{{else}} unter{{end}} {{num .Target 4}})
+{{- end}}`,
+	},
+}
+
+// Dezimal- und Tausendertrennzeichen
+type numberFormat struct {
+	decimal, group string
+}
+
+// Nach Locale, sonst nach Sprache
+var numberFormats = map[string]numberFormat{
+	"en":    {".", ","},
+	"de":    {",", "."},
+	"de-ch": {",", "’"},
+	"de-li": {",", "’"},
+	"fr":    {",", " "},
+	"fr-ch": {",", " "},
+	"it":    {",", "."},
+	"it-ch": {",", "’"},
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+func localeLanguage(locale string) string {
+	lang, _, _ := strings.Cut(normalizeLocale(locale), "-")
+	return lang
+}
+
+func numberFormatFor(locale string) numberFormat {
+	if nf, ok := numberFormats[normalizeLocale(locale)]; ok {
+		return nf
+	}
+	if nf, ok := numberFormats[localeLanguage(locale)]; ok {
+		return nf
+	}
+	return numberFormats["en"]
+}
+
+// Eingebaute Vorlagen der Sprache, sonst Englisch
+func builtinMessagesFor(locale string) messageTemplates {
+	if mt, ok := builtinMessages[localeLanguage(locale)]; ok {
+		return mt
+	}
+	return builtinMessages["en"]
+}
+
+// Zahl mit festen Nachkommastellen (-1 = so wenige wie nötig)
+func (nf numberFormat) format(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, r := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(nf.group)
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(nf.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Funktionen in Meldungsvorlagen
+func messageFuncs(locale string) template.FuncMap {
+	nf := numberFormatFor(locale)
+	funcs := template.FuncMap{
+		"num": nf.format,
+		"signed": func(v float64, decimals int) string {
+			s := nf.format(v, decimals)
+			if !strings.HasPrefix(s, "-") && v != 0 {
+				s = "+" + s
+			}
+			return s
+		},
+	}
+	for name, fn := range templateFuncs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// Übersetzte Vorlagen je Locale und Text
+var (
+	messageTmplMu    sync.Mutex
+	messageTmplCache = map[string]*template.Template{}
+)
+
+func compileMessageTemplate(locale, text string) (*template.Template, error) {
+	key := normalizeLocale(locale) + "\x00" + text
+
+	messageTmplMu.Lock()
+	defer messageTmplMu.Unlock()
+	if t, ok := messageTmplCache[key]; ok {
+		return t, nil
+	}
+
+	t, err := template.New("message").Funcs(messageFuncs(locale)).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	messageTmplCache[key] = t
+	return t, nil
+}
+
+func executeMessageTemplate(locale, text string, m alarmMessage) (string, error) {
+	t, err := compileMessageTemplate(locale, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, m); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Titel und Text: Vorlage des Alarms, sonst global, sonst eingebaut
+func renderAlarmMessage(cfg Config, a Alarm, m alarmMessage) (title, body string) {
+	locale := cfg.Messages.Locale
+	builtin := builtinMessagesFor(locale)
+
+	render := func(what string, texts ...string) string {
+		for _, text := range texts {
+			if text == "" {
+				continue
+			}
+			out, err := executeMessageTemplate(locale, text, m)
+			if err == nil {
+				return out
+			}
+			fmt.Printf("alarm %s %s template: %v\n", a.ID, what, err)
+		}
+		return ""
+	}
+
+	title = render("title", a.TitleTemplate, cfg.Messages.TitleTemplate, builtin.title)
+	body = render("message", a.MessageTemplate, cfg.Messages.MessageTemplate, builtin.body)
+	return title, body
+}
+
+// Vorlage prüfen (Syntax und Ausführung mit Beispielwerten)
+func validateMessageTemplate(locale, text string) error {
+	if text == "" {
+		return nil
+	}
+	_, err := executeMessageTemplate(locale, text, alarmMessage{
+		Kind:      kindLevel,
+		Pair:      "EUR/CHF",
+		Rate:      0.9312,
+		Target:    0.93,
+		Direction: dirAbove,
+		Time:      time.Now(),
+	})
+	return err
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lxn/walk"
 )
@@ -9,15 +10,15 @@ import (
 // Definitionen
 // PairRow für UI-Tabelle
 type PairRow struct {
-	From string
-	To   string
+	From           string
+	To             string
+	Direct         bool
+	RefreshSeconds int
 }
 
 // AlarmRow für UI-Tabelle
 type AlarmRow struct {
-	Pair      string
-	Target    float64
-	Direction string
+	Alarm
 }
 
 // TableModel für Currency Pairs
@@ -30,7 +31,12 @@ type PairTableModel struct {
 func NewPairTableModel(pairs []CurrencyPair) *PairTableModel {
 	m := &PairTableModel{}
 	for _, p := range pairs {
-		m.items = append(m.items, PairRow{From: p.From, To: p.To})
+		m.items = append(m.items, PairRow{
+			From:           p.From,
+			To:             p.To,
+			Direct:         p.Direct,
+			RefreshSeconds: p.RefreshSeconds,
+		})
 	}
 	return m
 }
@@ -46,6 +52,11 @@ func (m *PairTableModel) Value(row, col int) interface{} {
 		return item.From
 	case 1:
 		return item.To
+	case 2:
+		if item.RefreshSeconds <= 0 {
+			return "default"
+		}
+		return formatAge(time.Duration(item.RefreshSeconds) * time.Second)
 	}
 	return ""
 }
@@ -60,11 +71,7 @@ type AlarmTableModel struct {
 func NewAlarmTableModel(alarms []Alarm) *AlarmTableModel {
 	m := &AlarmTableModel{}
 	for _, a := range alarms {
-		m.items = append(m.items, AlarmRow{
-			Pair:      a.Pair,
-			Target:    a.Target,
-			Direction: a.Direction,
-		})
+		m.items = append(m.items, AlarmRow{Alarm: a})
 	}
 	return m
 }
@@ -75,13 +82,71 @@ func (m *AlarmTableModel) RowCount() int {
 
 func (m *AlarmTableModel) Value(row, col int) interface{} {
 	item := m.items[row]
+	if item.Direction == dirExpression {
+		switch col {
+		case 0:
+			return "(expr)"
+		case 1:
+			return item.Expression
+		case 2:
+			return item.Direction
+		case 3:
+			return alarmStatus(item.Alarm, time.Now())
+		}
+		return ""
+	}
 	switch col {
 	case 0:
 		return item.Pair
 	case 1:
+		if isChangeDirection(item.Direction) {
+			return fmt.Sprintf("%.2f%%", item.Target)
+		}
 		return fmt.Sprintf("%.4f", item.Target)
 	case 2:
+		if isChangeDirection(item.Direction) {
+			return item.Direction + " (" + referenceLabel(item.Alarm) + ")"
+		}
+		if isCrossDirection(item.Direction) && item.Hysteresis != 0 {
+			return fmt.Sprintf("%s (±%.4f)", item.Direction, item.Hysteresis)
+		}
 		return item.Direction
+	case 3:
+		return alarmStatus(item.Alarm, time.Now())
+	}
+	return ""
+}
+
+// TableModel für Alarm-Protokoll (neueste zuerst)
+type AlarmEventTableModel struct {
+	walk.TableModelBase
+	items []alarmEvent
+}
+
+func NewAlarmEventTableModel(events []alarmEvent) *AlarmEventTableModel {
+	return &AlarmEventTableModel{items: events}
+}
+
+func (m *AlarmEventTableModel) RowCount() int {
+	return len(m.items)
+}
+
+func (m *AlarmEventTableModel) Value(row, col int) interface{} {
+	item := m.items[row]
+	switch col {
+	case 0:
+		return item.Time.Local().Format("2006-01-02 15:04:05")
+	case 1:
+		return item.Rule
+	case 2:
+		if item.Rate == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%.4f", item.Rate)
+	case 3:
+		return item.Delivery
+	case 4:
+		return item.Output
 	}
 	return ""
 }
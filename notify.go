@@ -0,0 +1,298 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gen2brain/beeep"
+)
+
+// Benachrichtigungskanäle
+
+// Benachrichtigung eines ausgelösten Alarms
+type Notification struct {
+	AlarmID   string    `json:"alarm_id,omitempty"`
+	Rule      string    `json:"rule"`
+	Pair      string    `json:"pair,omitempty"`
+	Rate      float64   `json:"rate,omitempty"`
+	Target    float64   `json:"target,omitempty"`
+	Direction string    `json:"direction,omitempty"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Time      time.Time `json:"time"`
+	Channels  []string  `json:"channels,omitempty"`
+}
+
+// Felder für Vorlagen, z.B. {{.Pair}} oder {{json .Message}}
+type templateData struct {
+	AlarmID   string
+	Rule      string
+	Pair      string
+	Rate      float64
+	Target    float64
+	Direction string
+	Title     string
+	Message   string
+	Timestamp time.Time
+}
+
+func newTemplateData(n Notification) templateData {
+	return templateData{
+		AlarmID:   n.AlarmID,
+		Rule:      n.Rule,
+		Pair:      n.Pair,
+		Rate:      n.Rate,
+		Target:    n.Target,
+		Direction: n.Direction,
+		Title:     n.Title,
+		Message:   n.Message,
+		Timestamp: n.Time,
+	}
+}
+
+// Funktionen in Vorlagen
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// Notifier definition
+type Notifier interface {
+	// Name des Kanals wie in fxtray.json
+	Name() string
+	Notify(n Notification) error
+}
+
+// Kanäle, die die Alarme eines Durchlaufs zusammenfassen (z.B. E-Mail)
+type BatchNotifier interface {
+	Notifier
+	NotifyBatch(ns []Notification) error
+}
+
+// Kanäle mit Ausgabe für das Alarm-Protokoll (z.B. externe Befehle)
+type OutputNotifier interface {
+	Notifier
+	NotifyOutput(n Notification) (string, error)
+}
+
+const (
+	desktopChannel        = "desktop"
+	defaultNotifyRetries  = 2
+	defaultNotifyBackoff  = 2 * time.Second
+	notifyFailureTitle    = "FX Alarm delivery failed"
+	notifyFailureCooldown = 5 * time.Minute
+)
+
+// Registrierte Kanaltypen
+var notifierFactories = map[string]func(ch ChannelConfig, cfg Config) (Notifier, error){
+	"desktop": func(ch ChannelConfig, cfg Config) (Notifier, error) {
+		return desktopNotifier{name: ch.Name}, nil
+	},
+	"webhook": newWebhookNotifier,
+	"email":   newEmailNotifier,
+	"ntfy":    newNtfyNotifier,
+	"command": newCommandNotifier,
+}
+
+// Kanal nach Name; "desktop" ist immer vorhanden
+func notifierByName(cfg Config, name string) (Notifier, ChannelConfig, error) {
+	for _, ch := range cfg.Channels {
+		if ch.Name != name {
+			continue
+		}
+		factory, ok := notifierFactories[strings.ToLower(ch.Type)]
+		if !ok {
+			return nil, ch, fmt.Errorf("channel %s: unknown type %q", name, ch.Type)
+		}
+		n, err := factory(ch, cfg)
+		return n, ch, err
+	}
+	if name == desktopChannel {
+		ch := ChannelConfig{Name: desktopChannel, Type: "desktop"}
+		return desktopNotifier{name: desktopChannel}, ch, nil
+	}
+	return nil, ChannelConfig{}, fmt.Errorf("unknown channel %q", name)
+}
+
+// Kanäle eines Alarms, ohne Angabe der Desktop
+func alarmChannels(a Alarm) []string {
+	if len(a.Channels) == 0 {
+		return []string{desktopChannel}
+	}
+	return a.Channels
+}
+
+// Zustellen je Kanal mit Retry, Ergebnis ins Alarm-Protokoll
+func dispatchNotifications(cfg Config, batch []Notification) {
+	if len(batch) == 0 {
+		return
+	}
+
+	go func() {
+		// Ruhezeiten und Stundenlimit
+		batch, held := holdNotifications(cfg, batch, time.Now())
+
+		byChannel := map[string][]Notification{}
+		for _, n := range batch {
+			for _, name := range n.Channels {
+				byChannel[name] = append(byChannel[name], n)
+			}
+		}
+
+		failures := map[int][]string{}
+		outputs := map[int][]string{}
+		names := make([]string, 0, len(byChannel))
+		for name := range byChannel {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			ns := byChannel[name]
+			errs, outs := deliverChannel(cfg, name, ns)
+			for i, err := range errs {
+				idx := indexOfNotification(batch, ns[i])
+				if outs[i] != "" {
+					outputs[idx] = append(outputs[idx], name+": "+outs[i])
+				}
+				if err == nil {
+					continue
+				}
+				fmt.Printf("notify %s: %v\n", name, err)
+				failures[idx] = append(failures[idx], name+": "+err.Error())
+			}
+		}
+
+		for i, n := range batch {
+			delivery := "ok"
+			switch {
+			case len(failures[i]) > 0:
+				delivery = "failed: " + strings.Join(failures[i], "; ")
+				reportDeliveryFailure(cfg, n, failures[i])
+			case len(held[i]) > 0:
+				delivery = "queued: " + strings.Join(held[i], ", ")
+			}
+			if err := logAlarmEvent(alarmEvent{
+				Time:     n.Time,
+				AlarmID:  n.AlarmID,
+				Pair:     n.Pair,
+				Rate:     n.Rate,
+				Rule:     n.Rule,
+				Message:  n.Message,
+				Delivery: delivery,
+				Output:   strings.Join(outputs[i], "\n"),
+			}); err != nil {
+				fmt.Println("logAlarmEvent:", err)
+			}
+		}
+	}()
+}
+
+// Benachrichtigungen über einen Kanal, Fehler und Ausgabe je Benachrichtigung
+func deliverChannel(cfg Config, name string, ns []Notification) ([]error, []string) {
+	errs := make([]error, len(ns))
+	outs := make([]string, len(ns))
+
+	notifier, ch, err := notifierByName(cfg, name)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs, outs
+	}
+
+	retries := defaultNotifyRetries
+	switch {
+	case ch.Retries != nil:
+		retries = max(*ch.Retries, 0)
+	case strings.EqualFold(ch.Type, "command"):
+		// Befehle nicht ungefragt mehrfach ausführen
+		retries = 0
+	}
+
+	// Eine Nachricht für alle Alarme des Durchlaufs
+	if bn, ok := notifier.(BatchNotifier); ok && len(ns) > 1 {
+		err := withRetry(retries, func() error { return bn.NotifyBatch(ns) })
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs, outs
+	}
+
+	for i, n := range ns {
+		if on, ok := notifier.(OutputNotifier); ok {
+			errs[i] = withRetry(retries, func() error {
+				var err error
+				outs[i], err = on.NotifyOutput(n)
+				return err
+			})
+			continue
+		}
+		errs[i] = withRetry(retries, func() error { return notifier.Notify(n) })
+	}
+	return errs, outs
+}
+
+func withRetry(retries int, fn func() error) error {
+	var errs []error
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err)
+		if attempt >= retries {
+			return errors.Join(errs...)
+		}
+		time.Sleep(defaultNotifyBackoff << attempt)
+	}
+}
+
+// Fehlgeschlagene Zustellung auf dem Desktop melden (sofern dieser nicht selbst betroffen ist)
+func reportDeliveryFailure(cfg Config, n Notification, failures []string) {
+	for _, f := range failures {
+		if strings.HasPrefix(f, desktopChannel+":") {
+			return
+		}
+	}
+
+	triggeredMu.Lock()
+	last, exists := lastTriggered["delivery:"+n.AlarmID]
+	if exists && time.Since(last) < notifyFailureCooldown {
+		triggeredMu.Unlock()
+		return
+	}
+	lastTriggered["delivery:"+n.AlarmID] = time.Now()
+	triggeredMu.Unlock()
+
+	notifyDesktop(cfg, notifyFailureTitle, n.Rule+"\n"+strings.Join(failures, "\n"))
+}
+
+func indexOfNotification(batch []Notification, n Notification) int {
+	for i := range batch {
+		if batch[i].AlarmID == n.AlarmID && batch[i].Time.Equal(n.Time) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Desktop (beeep)
+type desktopNotifier struct {
+	name string
+}
+
+func (d desktopNotifier) Name() string {
+	return d.name
+}
+
+func (d desktopNotifier) Notify(n Notification) error {
+	return beeep.Notify(n.Title, n.Message, "")
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Externer Befehl: Alarm als Umgebungsvariablen und JSON auf stdin
+
+const (
+	defaultCommandTimeout = 30 * time.Second
+	maxCommandOutput      = 2048
+)
+
+// JSON auf stdin
+type commandPayload struct {
+	AlarmID   string    `json:"alarm_id"`
+	Rule      string    `json:"rule"`
+	Pair      string    `json:"pair,omitempty"`
+	Rate      float64   `json:"rate,omitempty"`
+	Target    float64   `json:"target,omitempty"`
+	Direction string    `json:"direction"`
+	Title     string    `json:"title"`
+	Message   string    `json:"message"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type commandNotifier struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+func newCommandNotifier(ch ChannelConfig, cfg Config) (Notifier, error) {
+	if ch.Command == "" {
+		return nil, fmt.Errorf("channel %s: command is required", ch.Name)
+	}
+	c := &commandNotifier{
+		name:    ch.Name,
+		command: ch.Command,
+		args:    ch.Args,
+		timeout: defaultCommandTimeout,
+	}
+	if ch.TimeoutSeconds > 0 {
+		c.timeout = time.Duration(ch.TimeoutSeconds) * time.Second
+	}
+	return c, nil
+}
+
+func (c *commandNotifier) Name() string {
+	return c.name
+}
+
+func (c *commandNotifier) Notify(n Notification) error {
+	_, err := c.NotifyOutput(n)
+	return err
+}
+
+// Befehl ausführen, stdout und stderr gekürzt zurückgeben
+func (c *commandNotifier) NotifyOutput(n Notification) (string, error) {
+	payload, err := json.Marshal(commandPayload{
+		AlarmID:   n.AlarmID,
+		Rule:      n.Rule,
+		Pair:      n.Pair,
+		Rate:      n.Rate,
+		Target:    n.Target,
+		Direction: n.Direction,
+		Title:     n.Title,
+		Message:   n.Message,
+		Timestamp: n.Time,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, c.command, c.args...)
+	cmd.Env = append(os.Environ(),
+		"FX_ALARM_ID="+n.AlarmID,
+		"FX_PAIR="+n.Pair,
+		"FX_RATE="+strconv.FormatFloat(n.Rate, 'f', -1, 64),
+		"FX_TARGET="+strconv.FormatFloat(n.Target, 'f', -1, 64),
+		"FX_DIRECTION="+n.Direction,
+		"FX_MESSAGE="+n.Message,
+	)
+	cmd.Stdin = bytes.NewReader(payload)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	// Hängende Kindprozesse nicht abwarten
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	output := strings.TrimSpace(out.String())
+	if len(output) > maxCommandOutput {
+		output = strings.ToValidUTF8(output[:maxCommandOutput], "") + "…"
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, fmt.Errorf("timed out after %s", c.timeout)
+	}
+	return output, err
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// E-Mail über SMTP mit STARTTLS und Anmeldung; Alarme eines Durchlaufs in einer Nachricht
+
+const (
+	defaultSMTPPort    = 587
+	defaultSMTPTimeout = 30 * time.Second
+)
+
+type emailNotifier struct {
+	name     string
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+	to       []string
+	startTLS bool
+	timeout  time.Duration
+}
+
+func newEmailNotifier(ch ChannelConfig, cfg Config) (Notifier, error) {
+	if ch.Host == "" {
+		return nil, fmt.Errorf("channel %s: host is required", ch.Name)
+	}
+	if ch.From == "" || len(ch.To) == 0 {
+		return nil, fmt.Errorf("channel %s: from and to are required", ch.Name)
+	}
+
+	e := &emailNotifier{
+		name:     ch.Name,
+		host:     ch.Host,
+		port:     ch.Port,
+		username: ch.Username,
+		password: ch.Password,
+		from:     ch.From,
+		to:       ch.To,
+		startTLS: !ch.DisableStartTLS,
+		timeout:  defaultSMTPTimeout,
+	}
+	if e.port == 0 {
+		e.port = defaultSMTPPort
+	}
+	if ch.TimeoutSeconds > 0 {
+		e.timeout = time.Duration(ch.TimeoutSeconds) * time.Second
+	}
+	return e, nil
+}
+
+func (e *emailNotifier) Name() string {
+	return e.name
+}
+
+func (e *emailNotifier) Notify(n Notification) error {
+	return e.NotifyBatch([]Notification{n})
+}
+
+func (e *emailNotifier) NotifyBatch(ns []Notification) error {
+	if len(ns) == 0 {
+		return nil
+	}
+
+	subject := ns[0].Title + ": " + ns[0].Rule
+	if len(ns) > 1 {
+		subject = fmt.Sprintf("%s: %d alarms", ns[0].Title, len(ns))
+	}
+
+	var body strings.Builder
+	for i, n := range ns {
+		if i > 0 {
+			body.WriteString("\r\n")
+		}
+		fmt.Fprintf(&body, "%s\r\n%s\r\n", n.Time.Local().Format("2006-01-02 15:04:05"), n.Message)
+	}
+
+	msg, err := e.message(subject, body.String())
+	if err != nil {
+		return err
+	}
+	return e.send(msg)
+}
+
+// Nachricht mit Kopfzeilen, Text als quoted-printable
+func (e *emailNotifier) message(subject, body string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", e.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return nil, err
+	}
+	if err := qp.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (e *emailNotifier) send(msg []byte) error {
+	addr := net.JoinHostPort(e.host, strconv.Itoa(e.port))
+	conn, err := net.DialTimeout("tcp", addr, e.timeout)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(e.timeout))
+
+	c, err := smtp.NewClient(conn, e.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.startTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("smtp server %s does not support STARTTLS", e.host)
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: e.host}); err != nil {
+			return err
+		}
+	}
+	if e.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.username, e.password, e.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(e.from); err != nil {
+		return err
+	}
+	for _, to := range e.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
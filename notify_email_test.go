@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"io"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Minimaler SMTP-Empfänger ohne STARTTLS: merkt sich DATA, Empfänger und Anmeldung
+type smtpSink struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	messages []string
+	rcpts    []string
+	auth     string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpSink{ln: ln}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *smtpSink) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	reply("220 localhost ESMTP sink")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-localhost")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN"):
+			s.mu.Lock()
+			s.auth = strings.TrimSpace(line[len("AUTH PLAIN"):])
+			s.mu.Unlock()
+			reply("235 ok")
+		case strings.HasPrefix(cmd, "MAIL FROM"):
+			reply("250 ok")
+		case strings.HasPrefix(cmd, "RCPT TO"):
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, line[len("RCPT TO:"):])
+			s.mu.Unlock()
+			reply("250 ok")
+		case cmd == "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				msg.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestEmailBatchesAlarmsIntoOneMessage(t *testing.T) {
+	sink := newSMTPSink(t)
+	cfg := Config{Channels: []ChannelConfig{{
+		Name:            "mail",
+		Type:            "email",
+		Host:            "127.0.0.1",
+		Port:            sink.port(),
+		Username:        "fxtray",
+		Password:        "pw",
+		From:            "fxtray@example.com",
+		To:              []string{"a@example.com", "b@example.com"},
+		DisableStartTLS: true,
+	}}}
+
+	var ns []Notification
+	for _, msg := range []string{"EUR/CHF alarm one", "USD/CHF alarm two", "GBP/USD alarm three"} {
+		n := testNotification()
+		n.Message = msg
+		ns = append(ns, n)
+	}
+
+	errs, _ := deliverChannel(cfg, "mail", ns)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("notification %d: %v", i, err)
+		}
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.messages) != 1 {
+		t.Fatalf("got %d DATA messages, want 1", len(sink.messages))
+	}
+	if len(sink.rcpts) != 2 {
+		t.Errorf("got %d recipients, want 2", len(sink.rcpts))
+	}
+	if want := base64.StdEncoding.EncodeToString([]byte("\x00fxtray\x00pw")); sink.auth != want {
+		t.Errorf("AUTH PLAIN = %q, want %q", sink.auth, want)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(sink.messages[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Header.Get("Subject"); got != "FX Alarm: 3 alarms" {
+		t.Errorf("Subject = %q", got)
+	}
+	body, err := io.ReadAll(quotedprintable.NewReader(m.Body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range ns {
+		if !strings.Contains(string(body), n.Message) {
+			t.Errorf("body does not contain %q:\n%s", n.Message, body)
+		}
+	}
+}
+
+func TestEmailRequiresStartTLS(t *testing.T) {
+	sink := newSMTPSink(t)
+	n, err := newEmailNotifier(ChannelConfig{
+		Name: "mail",
+		Type: "email",
+		Host: "127.0.0.1",
+		Port: sink.port(),
+		From: "fxtray@example.com",
+		To:   []string{"a@example.com"},
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(testNotification()); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("err = %v, want STARTTLS error", err)
+	}
+
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if len(sink.messages) != 0 {
+		t.Errorf("message sent without STARTTLS")
+	}
+}
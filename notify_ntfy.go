@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Push über das ntfy-Publish-Protokoll (ntfy.sh oder eigener Server)
+
+const defaultNtfyServer = "https://ntfy.sh"
+
+// Tags (Emoji-Kurznamen) je Alarm-Richtung
+var ntfyDirectionTags = map[string]string{
+	dirAbove:       "chart_with_upwards_trend",
+	dirBelow:       "chart_with_downwards_trend",
+	dirChangeUp:    "chart_with_upwards_trend",
+	dirChangeDown:  "chart_with_downwards_trend",
+	dirCrossesUp:   "arrow_up",
+	dirCrossesDown: "arrow_down",
+	dirCrosses:     "left_right_arrow",
+	dirExpression:  "bell",
+}
+
+// Priorität je Alarm-Richtung, sofern der Kanal keine feste Priorität setzt
+var ntfyDirectionPriority = map[string]string{
+	dirAbove:       "default",
+	dirBelow:       "default",
+	dirChangeUp:    "default",
+	dirChangeDown:  "default",
+	dirCrossesUp:   "high",
+	dirCrossesDown: "high",
+	dirCrosses:     "high",
+	dirExpression:  "high",
+}
+
+type ntfyNotifier struct {
+	name     string
+	url      string
+	priority string
+	tags     []string
+	token    string
+	username string
+	password string
+	client   *fxHTTPClient
+}
+
+func newNtfyNotifier(ch ChannelConfig, cfg Config) (Notifier, error) {
+	if ch.Topic == "" {
+		return nil, fmt.Errorf("channel %s: topic is required", ch.Name)
+	}
+	server := ch.Server
+	if server == "" {
+		server = defaultNtfyServer
+	}
+	client, err := httpClientFor(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ntfyNotifier{
+		name:     ch.Name,
+		url:      strings.TrimRight(server, "/") + "/" + strings.Trim(ch.Topic, "/"),
+		priority: ch.Priority,
+		tags:     ch.Tags,
+		token:    ch.Token,
+		username: ch.Username,
+		password: ch.Password,
+		client:   client,
+	}, nil
+}
+
+func (p *ntfyNotifier) Name() string {
+	return p.name
+}
+
+func (p *ntfyNotifier) Notify(n Notification) error {
+	req, err := http.NewRequest(http.MethodPost, p.url, strings.NewReader(n.Message))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	req.Header.Set("Title", mime.QEncoding.Encode("utf-8", n.Title))
+	priority := p.priority
+	if priority == "" {
+		priority = ntfyDirectionPriority[strings.ToLower(n.Direction)]
+	}
+	if priority != "" {
+		req.Header.Set("Priority", priority)
+	}
+
+	tags := append([]string(nil), p.tags...)
+	if tag, ok := ntfyDirectionTags[strings.ToLower(n.Direction)]; ok {
+		tags = append(tags, tag)
+	}
+	if n.Pair != "" {
+		tags = append(tags, strings.ReplaceAll(n.Pair, "/", ""))
+	}
+	if len(tags) > 0 {
+		req.Header.Set("Tags", strings.Join(tags, ","))
+	}
+
+	switch {
+	case p.token != "":
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	case p.username != "":
+		req.SetBasicAuth(p.username, p.password)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNtfyHeaders(t *testing.T) {
+	var path, body string
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		path, body, header = r.URL.Path, string(b), r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	n, err := newNtfyNotifier(ChannelConfig{
+		Name:     "phone",
+		Type:     "ntfy",
+		Server:   srv.URL + "/",
+		Topic:    "fx-alarms",
+		Priority: "high",
+		Tags:     []string{"fx"},
+		Token:    "tk_123",
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	alarm := testNotification()
+	alarm.Direction = dirCrossesUp
+	if err := n.Notify(alarm); err != nil {
+		t.Fatal(err)
+	}
+
+	if path != "/fx-alarms" {
+		t.Errorf("path = %q, want /fx-alarms", path)
+	}
+	if body != alarm.Message {
+		t.Errorf("body = %q, want %q", body, alarm.Message)
+	}
+	for name, want := range map[string]string{
+		"Title":         "FX Alarm",
+		"Priority":      "high",
+		"Tags":          "fx,arrow_up,EURCHF",
+		"Authorization": "Bearer tk_123",
+	} {
+		if got := header.Get(name); got != want {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestNtfyDirectionTags(t *testing.T) {
+	var tags, priority string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tags, priority = r.Header.Get("Tags"), r.Header.Get("Priority")
+	}))
+	defer srv.Close()
+
+	n, err := newNtfyNotifier(ChannelConfig{Name: "phone", Type: "ntfy", Server: srv.URL, Topic: "fx"}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir, tags, priority string
+	}{
+		{dirAbove, "chart_with_upwards_trend,EURCHF", "default"},
+		{dirChangeDown, "chart_with_downwards_trend,EURCHF", "default"},
+		{dirCrossesUp, "arrow_up,EURCHF", "high"},
+		{dirCrosses, "left_right_arrow,EURCHF", "high"},
+		{dirExpression, "bell,EURCHF", "high"},
+	}
+	for _, tt := range tests {
+		alarm := testNotification()
+		alarm.Direction = tt.dir
+		if err := n.Notify(alarm); err != nil {
+			t.Fatal(err)
+		}
+		if tags != tt.tags {
+			t.Errorf("%s: Tags = %q, want %q", tt.dir, tags, tt.tags)
+		}
+		if priority != tt.priority {
+			t.Errorf("%s: Priority = %q, want %q", tt.dir, priority, tt.priority)
+		}
+	}
+}
+
+func TestNtfyStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	n, err := newNtfyNotifier(ChannelConfig{Name: "phone", Type: "ntfy", Server: srv.URL, Topic: "fx"}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(testNotification()); err == nil {
+		t.Fatal("expected an error for status 403")
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+)
+
+// Webhook: POST mit JSON aus text/template, optional HMAC-Signatur
+
+const (
+	defaultWebhookTemplate = `{"text": {{json .Message}}, "pair": {{json .Pair}}, "rate": {{.Rate}}, ` +
+		`"target": {{.Target}}, "direction": {{json .Direction}}, "timestamp": {{json .Timestamp}}}`
+	defaultSignatureHeader = "X-FX-Signature"
+)
+
+type webhookNotifier struct {
+	name      string
+	url       string
+	headers   map[string]string
+	tmpl      *template.Template
+	secret    string
+	sigHeader string
+	client    *fxHTTPClient
+}
+
+func newWebhookNotifier(ch ChannelConfig, cfg Config) (Notifier, error) {
+	if ch.URL == "" {
+		return nil, fmt.Errorf("channel %s: url is required", ch.Name)
+	}
+	text := ch.Template
+	if text == "" {
+		text = defaultWebhookTemplate
+	}
+	tmpl, err := template.New(ch.Name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: template: %w", ch.Name, err)
+	}
+	client, err := httpClientFor(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &webhookNotifier{
+		name:      ch.Name,
+		url:       ch.URL,
+		headers:   ch.Headers,
+		tmpl:      tmpl,
+		secret:    ch.Secret,
+		sigHeader: ch.SignatureHeader,
+		client:    client,
+	}
+	if w.sigHeader == "" {
+		w.sigHeader = defaultSignatureHeader
+	}
+	return w, nil
+}
+
+func (w *webhookNotifier) Name() string {
+	return w.name
+}
+
+func (w *webhookNotifier) Notify(n Notification) error {
+	var body bytes.Buffer
+	if err := w.tmpl.Execute(&body, newTemplateData(n)); err != nil {
+		return fmt.Errorf("render template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return fmt.Errorf("template did not produce valid JSON")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range w.headers {
+		req.Header.Set(k, v)
+	}
+	if w.secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.secret))
+		mac.Write(body.Bytes())
+		req.Header.Set(w.sigHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
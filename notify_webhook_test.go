@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testNotification() Notification {
+	return Notification{
+		AlarmID:   "a1",
+		Rule:      "EUR/CHF above 0.9300",
+		Pair:      "EUR/CHF",
+		Rate:      0.9312,
+		Target:    0.93,
+		Direction: dirAbove,
+		Title:     "FX Alarm",
+		Message:   "EUR/CHF is now 0.9312 (target 0.9300 above)",
+		Time:      time.Date(2026, 10, 16, 14, 30, 0, 0, time.UTC),
+		Channels:  []string{"test"},
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	defer srv.Close()
+
+	n, err := newWebhookNotifier(ChannelConfig{
+		Name:    "hook",
+		Type:    "webhook",
+		URL:     srv.URL,
+		Headers: map[string]string{"X-Team": "treasury"},
+		Secret:  "s3cret",
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(testNotification()); err != nil {
+		t.Fatal(err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get("X-FX-Signature"); got != want {
+		t.Errorf("X-FX-Signature = %q, want %q", got, want)
+	}
+	if got := header.Get("X-Team"); got != "treasury" {
+		t.Errorf("X-Team = %q, want treasury", got)
+	}
+	if got := header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("body is not JSON: %v\n%s", err, body)
+	}
+	if payload["pair"] != "EUR/CHF" || payload["rate"] != 0.9312 {
+		t.Errorf("unexpected payload %s", body)
+	}
+}
+
+func TestWebhookRejectsInvalidJSON(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer srv.Close()
+
+	// Nachricht ohne json-Funktion: ungültiges JSON
+	n, err := newWebhookNotifier(ChannelConfig{
+		Name:     "hook",
+		Type:     "webhook",
+		URL:      srv.URL,
+		Template: `{"text": {{.Message}}}`,
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(testNotification()); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("server received %d requests, want 0", got)
+	}
+}
+
+func TestWebhookStatusError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	n, err := newWebhookNotifier(ChannelConfig{Name: "hook", Type: "webhook", URL: srv.URL}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Notify(testNotification()); err == nil {
+		t.Fatal("expected an error for status 502")
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Kursquellen
+
+// Kurstabelle einer Quelle (1 Base = Rate Ziel)
+type RateTable struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+	// Stand laut Quelle, nächste erwartete Aktualisierung (falls bekannt)
+	AsOf       time.Time `json:"as_of"`
+	NextUpdate time.Time `json:"next_update"`
+	// Abrufzeit; FromDisk bei Tabellen aus dem Cache
+	Fetched  time.Time `json:"fetched"`
+	FromDisk bool      `json:"-"`
+}
+
+// RateProvider definition
+type RateProvider interface {
+	// Name der Quelle wie in fxtray.json
+	Name() string
+	// Aktuelle Kurse für eine Basiswährung
+	Latest(base string) (*RateTable, error)
+	// Unterstützte Währungscodes
+	Currencies() ([]string, error)
+}
+
+const defaultProvider = "erapi"
+
+// Registrierte Quellen
+var providerFactories = map[string]func(cfg Config, client *fxHTTPClient) RateProvider{
+	"erapi": func(cfg Config, client *fxHTTPClient) RateProvider {
+		return newERAPIProvider(client, cfg.ProviderURLs["erapi"])
+	},
+	"ecb": func(cfg Config, client *fxHTTPClient) RateProvider {
+		return newECBProvider(client, cfg.ProviderURLs["ecb"])
+	},
+}
+
+// Quelle aus Config wählen (austauschbar, z.B. gegen lokale Fakes)
+var newRateProvider = func(cfg Config) (RateProvider, error) {
+	return newProviderChain(cfg)
+}
+
+func providerByName(cfg Config, name string) (RateProvider, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultProvider
+	}
+	factory, ok := providerFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rate provider %q", name)
+	}
+	client, err := httpClientFor(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return factory(cfg, client), nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Antwort EZB eurofxref-daily.xml
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+const ecbDailyURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// Zeitzone des Referenzkurses (tzdata ist eingebettet)
+var frankfurt = mustLoadLocation("Europe/Berlin")
+
+// Quelle EZB-Referenzkurse (EUR-basiert, Kreuzkurse werden abgeleitet)
+type ecbProvider struct {
+	client *fxHTTPClient
+	url    string
+}
+
+func newECBProvider(client *fxHTTPClient, url string) *ecbProvider {
+	if url == "" {
+		url = ecbDailyURL
+	}
+	return &ecbProvider{client: client, url: url}
+}
+
+func (p *ecbProvider) Name() string {
+	return "ecb"
+}
+
+func (p *ecbProvider) Latest(base string) (*RateTable, error) {
+	eur, asOf, err := p.fetch()
+	if err != nil {
+		return nil, err
+	}
+
+	base = strings.ToUpper(strings.TrimSpace(base))
+	baseRate, ok := eur[base]
+	if !ok {
+		return nil, fmt.Errorf("ecb: no reference rate for %s", base)
+	}
+
+	// Kreuzkurs: BASE/X = EUR/X ÷ EUR/BASE
+	out := make(map[string]float64, len(eur))
+	for code, rate := range eur {
+		out[code] = rate / baseRate
+	}
+	return &RateTable{Base: base, Rates: out, AsOf: asOf}, nil
+}
+
+func (p *ecbProvider) Currencies() ([]string, error) {
+	eur, _, err := p.fetch()
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(eur))
+	for code := range eur {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// Tagesfixing laden, Kurse je EUR (inkl. EUR = 1) und Datum
+func (p *ecbProvider) fetch() (map[string]float64, time.Time, error) {
+	body, err := p.client.GetConditional(p.url)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("ecb: %w", err)
+	}
+
+	var env ecbEnvelope
+	if err := xml.Unmarshal(body, &env); err != nil {
+		return nil, time.Time{}, err
+	}
+	if len(env.Cube.Days) == 0 {
+		return nil, time.Time{}, fmt.Errorf("ecb: no reference rates in feed")
+	}
+
+	day := env.Cube.Days[0]
+	eur := map[string]float64{"EUR": 1}
+	for _, r := range day.Rates {
+		if r.Rate <= 0 {
+			continue
+		}
+		eur[strings.ToUpper(r.Currency)] = r.Rate
+	}
+
+	// Fixing wird um 16:00 Frankfurter Zeit (CET/CEST) veröffentlicht
+	asOf, err := time.Parse("2006-01-02", day.Time)
+	if err == nil {
+		asOf = time.Date(asOf.Year(), asOf.Month(), asOf.Day(), 16, 0, 0, 0, frankfurt)
+	}
+	return eur, asOf, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Antwort API https://open.er-api.com/v6/latest/{BASE}
+type rateResponse struct {
+	Result             string             `json:"result"`
+	BaseCode           string             `json:"base_code"`
+	TimeLastUpdateUnix int64              `json:"time_last_update_unix"`
+	TimeNextUpdateUnix int64              `json:"time_next_update_unix"`
+	Rates              map[string]float64 `json:"rates"`
+}
+
+const erAPIBaseURL = "https://open.er-api.com/v6/latest/"
+
+// Quelle open.er-api.com
+type erAPIProvider struct {
+	client  *fxHTTPClient
+	baseURL string
+}
+
+func newERAPIProvider(client *fxHTTPClient, baseURL string) *erAPIProvider {
+	if baseURL == "" {
+		baseURL = erAPIBaseURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &erAPIProvider{client: client, baseURL: baseURL}
+}
+
+func (p *erAPIProvider) Name() string {
+	return "erapi"
+}
+
+func (p *erAPIProvider) Latest(base string) (*RateTable, error) {
+	rr, err := p.fetch(base)
+	if err != nil {
+		return nil, err
+	}
+	rt := &RateTable{
+		Base:  strings.ToUpper(rr.BaseCode),
+		Rates: rr.Rates,
+	}
+	if rr.TimeLastUpdateUnix > 0 {
+		rt.AsOf = time.Unix(rr.TimeLastUpdateUnix, 0)
+	}
+	if rr.TimeNextUpdateUnix > 0 {
+		rt.NextUpdate = time.Unix(rr.TimeNextUpdateUnix, 0)
+	}
+	return rt, nil
+}
+
+func (p *erAPIProvider) Currencies() ([]string, error) {
+	rr, err := p.fetch("USD")
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(rr.Rates))
+	for code := range rr.Rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes, nil
+}
+
+// API Call
+func (p *erAPIProvider) fetch(base string) (*rateResponse, error) {
+	body, err := p.client.GetConditional(p.baseURL + strings.ToUpper(base))
+	if err != nil {
+		return nil, fmt.Errorf("fx api: %w", err)
+	}
+
+	var rr rateResponse
+	if err := json.Unmarshal(body, &rr); err != nil {
+		return nil, err
+	}
+	if rr.Result != "success" {
+		return nil, fmt.Errorf("fx api returned result=%s", rr.Result)
+	}
+
+	return &rr, nil
+}
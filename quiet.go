@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ruhezeiten: betroffene Kanäle sammeln Alarme und stellen sie danach als Sammelmeldung zu;
+// optional höchstens MaxPerHour Benachrichtigungen je Stunde
+
+const quietFlushEvery = time.Minute
+
+// Zurückgehaltene Alarme je Kanal, Zeitpunkte der letzten Zustellungen
+var (
+	quietMu           sync.Mutex
+	heldNotifications = map[string][]Notification{}
+	sentTimes         []time.Time
+)
+
+func heldNotificationsPath() string {
+	return configSiblingPath(".queue.json")
+}
+
+// Zurückgehaltene Alarme beim Start laden
+func loadHeldNotifications() error {
+	data, err := os.ReadFile(heldNotificationsPath())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	held := map[string][]Notification{}
+	if err := json.Unmarshal(data, &held); err != nil {
+		return err
+	}
+
+	quietMu.Lock()
+	heldNotifications = held
+	quietMu.Unlock()
+	return nil
+}
+
+// Warteschlange speichern (Aufrufer hält quietMu); leer = Datei entfernen
+func saveHeldNotifications() error {
+	if len(heldNotifications) == 0 {
+		err := os.Remove(heldNotificationsPath())
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	data, err := json.MarshalIndent(heldNotifications, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(heldNotificationsPath(), data, 0644)
+}
+
+// Ruhezeit für den Kanal (Uhrzeit lokal, "22:00"–"07:00" auch über Mitternacht)
+func quietNow(q QuietConfig, channel string, now time.Time) bool {
+	if !quietChannel(q, channel) {
+		return false
+	}
+
+	day := strings.ToLower(now.Weekday().String()[:3])
+	for _, d := range q.Days {
+		if d = strings.ToLower(strings.TrimSpace(d)); len(d) >= 3 && d[:3] == day {
+			return true
+		}
+	}
+
+	start, ok1 := parseClock(q.Start)
+	end, ok2 := parseClock(q.End)
+	if !ok1 || !ok2 || start == end {
+		return false
+	}
+	m := now.Hour()*60 + now.Minute()
+	if start < end {
+		return m >= start && m < end
+	}
+	return m >= start || m < end
+}
+
+// Betroffene Kanäle, Standard nur Desktop
+func quietChannel(q QuietConfig, channel string) bool {
+	if len(q.Channels) == 0 {
+		return channel == desktopChannel
+	}
+	for _, name := range q.Channels {
+		if name == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// "HH:MM" in Minuten seit Mitternacht
+func parseClock(s string) (int, bool) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// Stundenlimit noch nicht erreicht (Aufrufer hält quietMu)
+func underHourlyLimit(q QuietConfig, now time.Time) bool {
+	cutoff := now.Add(-time.Hour)
+	kept := sentTimes[:0]
+	for _, t := range sentTimes {
+		if t.After(cutoff) {
+			kept = append(kept, t)
+		}
+	}
+	sentTimes = kept
+	return q.MaxPerHour <= 0 || len(sentTimes) < q.MaxPerHour
+}
+
+// Kanäle in Ruhezeit (oder alle bei erreichtem Limit) zurückhalten;
+// liefert die Benachrichtigungen mit den verbleibenden Kanälen und die zurückgehaltenen je Index
+func holdNotifications(cfg Config, batch []Notification, now time.Time) ([]Notification, map[int][]string) {
+	quietMu.Lock()
+	defer quietMu.Unlock()
+
+	q := cfg.Quiet
+	out := make([]Notification, len(batch))
+	held := map[int][]string{}
+	for i, n := range batch {
+		limited := !underHourlyLimit(q, now)
+
+		var channels []string
+		for _, name := range n.Channels {
+			if limited || quietNow(q, name, now) {
+				heldNotifications[name] = append(heldNotifications[name], n)
+				held[i] = append(held[i], name)
+				continue
+			}
+			channels = append(channels, name)
+		}
+		if len(channels) > 0 {
+			sentTimes = append(sentTimes, now)
+		}
+		n.Channels = channels
+		out[i] = n
+	}
+	if len(held) > 0 {
+		if err := saveHeldNotifications(); err != nil {
+			fmt.Println("saveHeldNotifications:", err)
+		}
+	}
+	return out, held
+}
+
+// Zurückgehaltene Alarme nach der Ruhezeit als eine Sammelmeldung je Kanal zustellen
+func flushHeldNotifications(cfg Config, now time.Time) {
+	quietMu.Lock()
+	due := map[string][]Notification{}
+	if underHourlyLimit(cfg.Quiet, now) {
+		for name, ns := range heldNotifications {
+			if quietNow(cfg.Quiet, name, now) {
+				continue
+			}
+			due[name] = ns
+			delete(heldNotifications, name)
+		}
+		if len(due) > 0 {
+			sentTimes = append(sentTimes, now)
+			if err := saveHeldNotifications(); err != nil {
+				fmt.Println("saveHeldNotifications:", err)
+			}
+		}
+	}
+	quietMu.Unlock()
+
+	names := make([]string, 0, len(due))
+	for name := range due {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		digest := digestNotification(due[name], now)
+		errs, outs := deliverChannel(cfg, name, []Notification{digest})
+
+		delivery := "ok"
+		if errs[0] != nil {
+			fmt.Printf("notify %s: %v\n", name, errs[0])
+			delivery = "failed: " + name + ": " + errs[0].Error()
+			reportDeliveryFailure(cfg, digest, []string{name + ": " + errs[0].Error()})
+		}
+		output := ""
+		if outs[0] != "" {
+			output = name + ": " + outs[0]
+		}
+		refs := make([]alarmRef, 0, len(due[name]))
+		for _, n := range due[name] {
+			refs = append(refs, alarmRef{AlarmID: n.AlarmID, Time: n.Time})
+		}
+		if err := logAlarmEvent(alarmEvent{
+			Time:     now,
+			AlarmID:  digest.AlarmID,
+			Pair:     digest.Pair,
+			Rate:     digest.Rate,
+			Rule:     digest.Rule + " (held for " + name + ")",
+			Message:  digest.Message,
+			Delivery: delivery,
+			Output:   output,
+			DigestOf: refs,
+		}); err != nil {
+			fmt.Println("logAlarmEvent:", err)
+		}
+	}
+}
+
+// Eine Meldung mit allen zurückgehaltenen Alarmen
+func digestNotification(ns []Notification, now time.Time) Notification {
+	if len(ns) == 1 {
+		return ns[0]
+	}
+
+	lines := make([]string, 0, len(ns))
+	for _, n := range ns {
+		lines = append(lines, n.Time.Local().Format("15:04")+" "+n.Message)
+	}
+	return Notification{
+		Rule:    fmt.Sprintf("%d alarms", len(ns)),
+		Title:   fmt.Sprintf("%s (%d)", ns[0].Title, len(ns)),
+		Message: strings.Join(lines, "\n"),
+		Time:    now,
+	}
+}
+
+// Desktop-Meldung außerhalb von Alarmen (Abweichungen, Zustellfehler), ebenfalls mit Ruhezeit und Limit
+func notifyDesktop(cfg Config, title, msg string) {
+	n := Notification{
+		Rule:     title,
+		Title:    title,
+		Message:  msg,
+		Time:     time.Now(),
+		Channels: []string{desktopChannel},
+	}
+	batch, _ := holdNotifications(cfg, []Notification{n}, n.Time)
+	if len(batch[0].Channels) == 0 {
+		return
+	}
+	if err := (desktopNotifier{name: desktopChannel}).Notify(n); err != nil {
+		fmt.Println("notify desktop:", err)
+	}
+}
+
+// Regelmässig prüfen, ob Ruhezeit oder Limit vorbei sind
+func quietLoop() {
+	for {
+		configMu.RLock()
+		cfg := currentConfig
+		configMu.RUnlock()
+
+		flushHeldNotifications(cfg, time.Now())
+		time.Sleep(quietFlushEvery)
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const minInterval = 10 * time.Second
+
+// Letzter Abruf je Paar, Signal für neuen Zeitplan
+var (
+	scheduleMu      sync.Mutex
+	pairLastRefresh = map[string]time.Time{}
+
+	rescheduleChan = make(chan struct{}, 1)
+)
+
+// Intervall global aus Config, sonst Standard
+func refreshInterval(cfg Config) time.Duration {
+	if cfg.RefreshSeconds <= 0 {
+		return defaultInterval
+	}
+	return max(time.Duration(cfg.RefreshSeconds)*time.Second, minInterval)
+}
+
+// Intervall je Paar, überschreibt das globale
+func pairInterval(cfg Config, p CurrencyPair) time.Duration {
+	if p.RefreshSeconds <= 0 {
+		return refreshInterval(cfg)
+	}
+	return max(time.Duration(p.RefreshSeconds)*time.Second, minInterval)
+}
+
+// Paare mit Zeitplan: konfigurierte Paare und reine Konsens-Paare (globales Intervall)
+func scheduledPairs(cfg Config) []CurrencyPair {
+	pairs := append([]CurrencyPair(nil), cfg.Pairs...)
+	if cfg.Consensus == nil {
+		return pairs
+	}
+
+	seen := map[string]bool{}
+	for _, p := range cfg.Pairs {
+		seen[pairKey(p.From, p.To)] = true
+	}
+	for _, pair := range cfg.Consensus.Pairs {
+		key := normalizeAlarmPair(pair)
+		from, to, ok := strings.Cut(key, "/")
+		if !ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		pairs = append(pairs, CurrencyPair{From: from, To: to})
+	}
+	return pairs
+}
+
+// Fällige Paare (noch nie oder vor mehr als einem Intervall abgerufen)
+func duePairs(cfg Config, now time.Time) map[string]bool {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	due := map[string]bool{}
+	for _, p := range scheduledPairs(cfg) {
+		key := pairKey(p.From, p.To)
+		last, ok := pairLastRefresh[key]
+		if !ok || !now.Before(last.Add(pairInterval(cfg, p))) {
+			due[key] = true
+		}
+	}
+	return due
+}
+
+func markPairsRefreshed(pairs []CurrencyPair, now time.Time) {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+	for _, p := range pairs {
+		pairLastRefresh[pairKey(p.From, p.To)] = now
+	}
+}
+
+// Nächster fälliger Abruf über alle Paare
+func nextDueTime(cfg Config) time.Time {
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
+	var next time.Time
+	for _, p := range scheduledPairs(cfg) {
+		last, ok := pairLastRefresh[pairKey(p.From, p.To)]
+		if !ok {
+			return time.Now()
+		}
+		t := last.Add(pairInterval(cfg, p))
+		if next.IsZero() || t.Before(next) {
+			next = t
+		}
+	}
+	return next
+}
+
+// updateLoop neu planen (Config geändert, manueller Refresh)
+func requestReschedule() {
+	select {
+	case rescheduleChan <- struct{}{}:
+	default:
+	}
+}
+
+// Abrufe nach Zeitplan der Quelle
+
+// Letzte Tabelle je Quelle und Basis
+var (
+	tableCacheMu sync.Mutex
+	tableCache   = map[string]*RateTable{}
+)
+
+// Neue Daten erst ab NextUpdate der Quelle abrufen; Tabellen aus dem Datei-Cache
+// immer neu abrufen und nur bei Fehler (zusammen mit dem Fehler) liefern
+func latestScheduled(p RateProvider, base string, now time.Time) (*RateTable, error) {
+	key := p.Name() + ":" + strings.ToUpper(base)
+
+	tableCacheMu.Lock()
+	cached, ok := tableCache[key]
+	tableCacheMu.Unlock()
+	if ok && !cached.FromDisk && !cached.NextUpdate.IsZero() && now.Before(cached.NextUpdate) {
+		return cached, nil
+	}
+
+	rt, err := p.Latest(base)
+	if err != nil {
+		// Offline: Tabelle aus dem Datei-Cache mit Fehler zurückgeben
+		if ok && cached.FromDisk {
+			return cached, err
+		}
+		return nil, err
+	}
+	rt.Fetched = now
+
+	tableCacheMu.Lock()
+	tableCache[key] = rt
+	tableCacheMu.Unlock()
+	return rt, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Kursstatus je Paar
+
+// Letzter guter Kurs und letzter Fehler
+type pairState struct {
+	Rate    float64
+	Updated time.Time
+	AsOf    time.Time
+	Cached  bool
+	Err     error
+}
+
+// Kurs vorhanden, aber nicht aus dem letzten Abruf
+func (s pairState) stale() bool {
+	return s.Err != nil && !s.Updated.IsZero()
+}
+
+var pairStates = map[string]pairState{}
+
+// Ergebnis eines Abrufs übernehmen, fehlgeschlagene Paare behalten den letzten Kurs;
+// nicht fällige Paare (due != nil) bleiben unverändert
+func mergePairStates(cfg Config, fresh map[string]pairState, errs map[string]error, due map[string]bool) map[string]pairState {
+	ratesMu.Lock()
+	defer ratesMu.Unlock()
+
+	next := map[string]pairState{}
+	for _, p := range scheduledPairs(cfg) {
+		key := pairKey(p.From, p.To)
+		if due != nil && !due[key] {
+			if st, ok := pairStates[key]; ok {
+				next[key] = st
+			}
+			continue
+		}
+		if st, ok := fresh[key]; ok {
+			next[key] = st
+			continue
+		}
+		st := pairStates[key]
+		st.Err = errs[key]
+		if st.Err == nil {
+			st.Err = fmt.Errorf("no rate for %s", key)
+		}
+		next[key] = st
+	}
+	pairStates = next
+
+	out := make(map[string]pairState, len(next))
+	for k, v := range next {
+		out[k] = v
+	}
+	return out
+}
+
+func setPairStates(states map[string]pairState) {
+	ratesMu.Lock()
+	defer ratesMu.Unlock()
+	pairStates = states
+}
+
+func getPairStates() map[string]pairState {
+	ratesMu.RLock()
+	defer ratesMu.RUnlock()
+	out := make(map[string]pairState, len(pairStates))
+	for k, v := range pairStates {
+		out[k] = v
+	}
+	return out
+}
+
+// Neuester Stand über alle Paare, laut Quelle (sonst Abrufzeit)
+func latestAsOf(states map[string]pairState) time.Time {
+	var latest time.Time
+	for _, st := range states {
+		t := st.AsOf
+		if t.IsZero() {
+			t = st.Updated
+		}
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// Einträge für das Status-Menü
+func statusLines(cfg Config, states map[string]pairState, now time.Time) []string {
+	var lines []string
+	for _, p := range cfg.Pairs {
+		key := pairKey(p.From, p.To)
+		st, ok := states[key]
+		switch {
+		case !ok:
+			lines = append(lines, key+": pending")
+		case st.Err == nil:
+			lines = append(lines, key+": OK")
+		case st.stale():
+			lines = append(lines, fmt.Sprintf("%s: stale %s – %s", key, formatAge(now.Sub(st.Updated)), shorten(st.Err.Error(), 80)))
+		default:
+			lines = append(lines, fmt.Sprintf("%s: no data – %s", key, shorten(st.Err.Error(), 80)))
+		}
+	}
+	return lines
+}
+
+// Helper
+
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+func shorten(s string, max int) string {
+	r := []rune(s)
+	if len(r) <= max {
+		return s
+	}
+	return string(r[:max-1]) + "…"
+}
@@ -0,0 +1,49 @@
+package main
+
+import "strings"
+
+// Kreuzkurse
+
+const defaultTriangulationBase = "USD"
+
+// Abrufe je Basiswährung; bei Triangulation ein Abruf für alle nicht-direkten Paare
+func fetchPlan(cfg Config) map[string][]CurrencyPair {
+	triBase := strings.ToUpper(strings.TrimSpace(cfg.TriangulationBase))
+	if triBase == "" {
+		triBase = defaultTriangulationBase
+	}
+
+	plan := map[string][]CurrencyPair{}
+	for _, p := range cfg.Pairs {
+		base := strings.ToUpper(strings.TrimSpace(p.From))
+		if cfg.Triangulate && !p.Direct {
+			base = triBase
+		}
+		plan[base] = append(plan[base], p)
+	}
+	return plan
+}
+
+// FROM/TO aus einer Tabelle mit beliebiger Basis, z.B. CHF/JPY = USD/JPY ÷ USD/CHF
+func crossRate(rt *RateTable, from, to string) (float64, bool) {
+	from = strings.ToUpper(strings.TrimSpace(from))
+	to = strings.ToUpper(strings.TrimSpace(to))
+
+	baseRate := func(code string) (float64, bool) {
+		if code == rt.Base {
+			return 1, true
+		}
+		r, ok := rt.Rates[code]
+		return r, ok && r != 0
+	}
+
+	fromRate, ok := baseRate(from)
+	if !ok {
+		return 0, false
+	}
+	toRate, ok := baseRate(to)
+	if !ok {
+		return 0, false
+	}
+	return toRate / fromRate, true
+}
@@ -0,0 +1,391 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+)
+
+// Alarm-Dialog für Hinzufügen und Bearbeiten; pairs = in Ausdrücken verfügbare Paare
+func runAlarmDialog(owner walk.Form, title, okText string, initial Alarm, pairs []string) (Alarm, bool) {
+	var dlg *walk.Dialog
+	var pairEdit *walk.LineEdit
+	var targetEdit *walk.NumberEdit
+	var dirCombo *walk.ComboBox
+	var refCombo *walk.ComboBox
+	var windowEdit *walk.NumberEdit
+	var hysteresisEdit *walk.NumberEdit
+	var exprEdit *walk.LineEdit
+	var enabledCheck, oneShotCheck *walk.CheckBox
+	var expiresEdit, snoozeEdit *walk.LineEdit
+	var channelsEdit *walk.LineEdit
+	var titleTmplEdit, messageTmplEdit *walk.LineEdit
+
+	dirIndex := indexOf(alarmDirections, strings.ToLower(initial.Direction))
+	if dirIndex < 0 {
+		dirIndex = 0
+	}
+	refIndex := indexOf(alarmReferences, strings.ToLower(initial.Reference))
+	if refIndex < 0 {
+		refIndex = 0
+	}
+
+	result := initial
+
+	// Bezugspunkt nur für prozentuale Alarme, Hysterese nur für Kreuzungen
+	updateEnabled := func() {
+		dir := alarmDirections[max(dirCombo.CurrentIndex(), 0)]
+		isExpr := dir == dirExpression
+		pairEdit.SetEnabled(!isExpr)
+		targetEdit.SetEnabled(!isExpr)
+		exprEdit.SetEnabled(isExpr)
+		isChange := isChangeDirection(dir)
+		refCombo.SetEnabled(isChange)
+		windowEdit.SetEnabled(isChange && alarmReferences[max(refCombo.CurrentIndex(), 0)] == refWindow)
+		hysteresisEdit.SetEnabled(isCrossDirection(dir))
+	}
+
+	err := Dialog{
+		AssignTo: &dlg,
+		Title:    title,
+		MinSize:  Size{Width: 340, Height: 470},
+		Layout:   VBox{},
+		Children: []Widget{
+			Composite{
+				Layout: Grid{Columns: 2},
+				Children: []Widget{
+					Label{Text: "Pair (e.g. EUR/CHF):"},
+					LineEdit{
+						AssignTo: &pairEdit,
+						Text:     initial.Pair,
+					},
+					Label{Text: "Direction:"},
+					ComboBox{
+						AssignTo:              &dirCombo,
+						Model:                 alarmDirections,
+						CurrentIndex:          dirIndex,
+						OnCurrentIndexChanged: func() { updateEnabled() },
+					},
+					Label{Text: "Target (rate or %):"},
+					NumberEdit{
+						AssignTo: &targetEdit,
+						Value:    initial.Target,
+						Decimals: 4,
+					},
+					Label{Text: "Change vs.:"},
+					ComboBox{
+						AssignTo:              &refCombo,
+						Model:                 alarmReferences,
+						CurrentIndex:          refIndex,
+						OnCurrentIndexChanged: func() { updateEnabled() },
+					},
+					Label{Text: "Window (hours):"},
+					NumberEdit{
+						AssignTo: &windowEdit,
+						Value:    initial.WindowHours,
+						Decimals: 1,
+					},
+					Label{Text: "Hysteresis:"},
+					NumberEdit{
+						AssignTo: &hysteresisEdit,
+						Value:    initial.Hysteresis,
+						Decimals: 4,
+					},
+					Label{Text: "Expression:"},
+					LineEdit{
+						AssignTo:    &exprEdit,
+						Text:        initial.Expression,
+						ToolTipText: "e.g. EURCHF < 0.93 && USDCHF > 0.88",
+					},
+					Label{Text: "Expires:"},
+					LineEdit{
+						AssignTo:    &expiresEdit,
+						Text:        formatDialogTime(initial.ExpiresAt),
+						ToolTipText: "YYYY-MM-DD HH:MM, empty = never",
+					},
+					Label{Text: "Snoozed until:"},
+					LineEdit{
+						AssignTo:    &snoozeEdit,
+						Text:        formatDialogTime(initial.SnoozeUntil),
+						ToolTipText: "YYYY-MM-DD HH:MM, empty = not snoozed",
+					},
+					Label{Text: "Channels:"},
+					LineEdit{
+						AssignTo:    &channelsEdit,
+						Text:        strings.Join(initial.Channels, ", "),
+						ToolTipText: "Comma-separated channel names, empty = desktop",
+					},
+					Label{Text: "Title template:"},
+					LineEdit{
+						AssignTo:    &titleTmplEdit,
+						Text:        initial.TitleTemplate,
+						ToolTipText: "Go template, e.g. FX {{.Pair}}; empty = default",
+					},
+					Label{Text: "Message template:"},
+					LineEdit{
+						AssignTo:    &messageTmplEdit,
+						Text:        initial.MessageTemplate,
+						ToolTipText: "Go template, e.g. {{.Pair}} at {{num .Rate 4}}; empty = default",
+					},
+					CheckBox{
+						AssignTo: &enabledCheck,
+						Text:     "Enabled",
+						Checked:  !initial.Disabled,
+					},
+					CheckBox{
+						AssignTo: &oneShotCheck,
+						Text:     "One-shot (disable after firing)",
+						Checked:  initial.OneShot,
+					},
+				},
+			},
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					HSpacer{},
+					PushButton{
+						Text: okText,
+						OnClicked: func() {
+							pair := normalizeAlarmPair(pairEdit.Text())
+							target := targetEdit.Value()
+							dir := ""
+							if idx := dirCombo.CurrentIndex(); idx >= 0 && idx < len(alarmDirections) {
+								dir = alarmDirections[idx]
+							}
+
+							if dir == "" {
+								walk.MsgBox(dlg, "Validation",
+									"Please select a direction.",
+									walk.MsgBoxIconWarning)
+								return
+							}
+
+							expiresAt, err := parseDialogTime(expiresEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "Validation",
+									"Please enter the expiry as YYYY-MM-DD HH:MM.",
+									walk.MsgBoxIconWarning)
+								return
+							}
+							snoozeUntil, err := parseDialogTime(snoozeEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "Validation",
+									"Please enter the snooze end as YYYY-MM-DD HH:MM.",
+									walk.MsgBoxIconWarning)
+								return
+							}
+							channels, err := parseChannelList(channelsEdit.Text())
+							if err != nil {
+								walk.MsgBox(dlg, "Validation",
+									"Invalid channels: "+err.Error(),
+									walk.MsgBoxIconWarning)
+								return
+							}
+							configMu.RLock()
+							locale := currentConfig.Messages.Locale
+							configMu.RUnlock()
+							titleTmpl := strings.TrimSpace(titleTmplEdit.Text())
+							messageTmpl := strings.TrimSpace(messageTmplEdit.Text())
+							for _, text := range []string{titleTmpl, messageTmpl} {
+								if err := validateMessageTemplate(locale, text); err != nil {
+									walk.MsgBox(dlg, "Validation",
+										"Invalid template: "+err.Error(),
+										walk.MsgBoxIconWarning)
+									return
+								}
+							}
+							result.TitleTemplate = titleTmpl
+							result.MessageTemplate = messageTmpl
+							result.Channels = channels
+							result.ExpiresAt = expiresAt
+							result.SnoozeUntil = snoozeUntil
+							result.Disabled = !enabledCheck.Checked()
+							result.OneShot = oneShotCheck.Checked()
+
+							// Ausdruck statt Paar und Ziel
+							if dir == dirExpression {
+								expr := strings.TrimSpace(exprEdit.Text())
+								if err := validateAlarmExpression(expr, pairs); err != nil {
+									walk.MsgBox(dlg, "Validation",
+										"Invalid expression: "+err.Error(),
+										walk.MsgBoxIconWarning)
+									return
+								}
+								result = Alarm{
+									ID:          initial.ID,
+									Direction:   dir,
+									Expression:  expr,
+									CreatedAt:   initial.CreatedAt,
+									OneShot:     result.OneShot,
+									Disabled:    result.Disabled,
+									ExpiresAt:   result.ExpiresAt,
+									SnoozeUntil: result.SnoozeUntil,
+									Channels:    result.Channels,
+
+									TitleTemplate:   result.TitleTemplate,
+									MessageTemplate: result.MessageTemplate,
+								}
+								if result.CreatedAt.IsZero() {
+									result.CreatedAt = time.Now()
+								}
+								dlg.Accept()
+								return
+							}
+
+							if pair == "" {
+								walk.MsgBox(dlg, "Validation",
+									"Please enter a currency pair (e.g. EUR/CHF).",
+									walk.MsgBoxIconWarning)
+								return
+							}
+							if target == 0 {
+								walk.MsgBox(dlg, "Validation",
+									"Please enter a non-zero target.",
+									walk.MsgBoxIconWarning)
+								return
+							}
+
+							result.Pair = pair
+							result.Target = target
+							result.Direction = dir
+							result.Reference = ""
+							result.WindowHours = 0
+							result.Hysteresis = 0
+							result.Expression = ""
+							if isCrossDirection(dir) {
+								result.Hysteresis = hysteresisEdit.Value()
+							}
+
+							if isChangeDirection(dir) {
+								ref := alarmReferences[max(refCombo.CurrentIndex(), 0)]
+								if target < 0 {
+									walk.MsgBox(dlg, "Validation",
+										"Please enter the change as a positive percentage.",
+										walk.MsgBoxIconWarning)
+									return
+								}
+								if ref == refWindow && windowEdit.Value() <= 0 {
+									walk.MsgBox(dlg, "Validation",
+										"Please enter a window in hours.",
+										walk.MsgBoxIconWarning)
+									return
+								}
+								if ref != refCreated {
+									configMu.RLock()
+									historyOff := currentConfig.History.Disabled
+									configMu.RUnlock()
+									if historyOff {
+										walk.MsgBox(dlg, "Warning",
+											"The rate history is disabled, so this alarm cannot compare against "+
+												ref+" and will not fire until history is enabled.",
+											walk.MsgBoxIconWarning)
+									}
+								}
+								result.Reference = ref
+								if ref == refWindow {
+									result.WindowHours = windowEdit.Value()
+								}
+
+								// Kurs beim Anlegen festhalten
+								if ref == refCreated && (initial.BaseRate == 0 || initial.Pair != pair ||
+									initial.Reference != refCreated) {
+									ratesMu.RLock()
+									rate, ok := rates[pair]
+									ratesMu.RUnlock()
+									if !ok {
+										walk.MsgBox(dlg, "Validation",
+											"No current rate for "+pair+" yet, cannot use it as reference.",
+											walk.MsgBoxIconWarning)
+										return
+									}
+									result.BaseRate = rate
+								}
+							}
+							if result.Reference != refCreated {
+								result.BaseRate = 0
+							}
+							if result.CreatedAt.IsZero() {
+								result.CreatedAt = time.Now()
+							}
+
+							dlg.Accept()
+						},
+					},
+					PushButton{
+						Text:      "Cancel",
+						OnClicked: func() { dlg.Cancel() },
+					},
+				},
+			},
+		},
+	}.Create(owner)
+
+	if err != nil {
+		walk.MsgBox(owner, "Error", "Failed to open dialog: "+err.Error(), walk.MsgBoxIconError)
+		return initial, false
+	}
+
+	updateEnabled()
+	if dlg.Run() != walk.DlgCmdOK {
+		return initial, false
+	}
+	return result, true
+}
+
+// Helper
+
+const dialogTimeLayout = "2006-01-02 15:04"
+
+func formatDialogTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(dialogTimeLayout)
+}
+
+// Leer = kein Zeitpunkt; Datum ohne Uhrzeit = Tagesbeginn
+func parseDialogTime(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.ParseInLocation(dialogTimeLayout, s, time.Local); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", s, time.Local)
+}
+
+// Kanalnamen prüfen (müssen in fxtray.json definiert sein, "desktop" immer)
+func parseChannelList(text string) ([]string, error) {
+	configMu.RLock()
+	defined := map[string]bool{desktopChannel: true}
+	for _, ch := range currentConfig.Channels {
+		defined[ch.Name] = true
+	}
+	configMu.RUnlock()
+
+	var channels []string
+	for _, name := range strings.Split(text, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !defined[name] {
+			return nil, fmt.Errorf("unknown notification channel %q", name)
+		}
+		channels = append(channels, name)
+	}
+	return channels, nil
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
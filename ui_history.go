@@ -0,0 +1,77 @@
+package main
+
+import (
+	"github.com/lxn/walk"
+	. "github.com/lxn/walk/declarative"
+)
+
+// Tab "Alarm History" mit Export als CSV/JSON
+func alarmHistoryPage(owner **walk.MainWindow) TabPage {
+	model := NewAlarmEventTableModel(recentAlarms(recentAlarmEvents))
+
+	reload := func() {
+		model.items = recentAlarms(recentAlarmEvents)
+		model.PublishRowsReset()
+	}
+
+	export := func(filter, name string, write func(path string) error) {
+		dlg := walk.FileDialog{
+			Title:    "Export Alarm History",
+			Filter:   filter,
+			FilePath: name,
+		}
+		ok, err := dlg.ShowSave(*owner)
+		if err != nil {
+			walk.MsgBox(*owner, "Error", "Failed to open dialog: "+err.Error(), walk.MsgBoxIconError)
+			return
+		}
+		if !ok {
+			return
+		}
+		if err := write(dlg.FilePath); err != nil {
+			walk.MsgBox(*owner, "Error", "Export failed: "+err.Error(), walk.MsgBoxIconError)
+			return
+		}
+		walk.MsgBox(*owner, "Export", "Alarm history exported to "+dlg.FilePath, walk.MsgBoxIconInformation)
+	}
+
+	return TabPage{
+		Title:  "Alarm History",
+		Layout: VBox{},
+		Children: []Widget{
+			TableView{
+				AlternatingRowBG: true,
+				Columns: []TableViewColumn{
+					{Title: "Time", Width: 120},
+					{Title: "Rule", Width: 140},
+					{Title: "Rate", Width: 60},
+					{Title: "Delivery", Width: 80},
+					{Title: "Output", Width: 120},
+				},
+				Model: model,
+			},
+			Composite{
+				Layout: HBox{},
+				Children: []Widget{
+					PushButton{
+						Text:      "Reload",
+						OnClicked: reload,
+					},
+					PushButton{
+						Text: "Export CSV…",
+						OnClicked: func() {
+							export("CSV files (*.csv)|*.csv", "fxtray-alarms.csv", exportAlarmLogCSV)
+						},
+					},
+					PushButton{
+						Text: "Export JSON…",
+						OnClicked: func() {
+							export("JSON files (*.json)|*.json", "fxtray-alarms.json", exportAlarmLogJSON)
+						},
+					},
+					HSpacer{},
+				},
+			},
+		},
+	}
+}
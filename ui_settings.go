@@ -27,16 +27,23 @@ func openSettingsWindow() {
 	pairModel := NewPairTableModel(cfg.Pairs)
 	alarmModel := NewAlarmTableModel(cfg.Alarms)
 
+	// Im Fenster bearbeitete Alarme (nach ID); übrige übernehmen beim Speichern
+	// Schalter und Pausen, die inzwischen im Tray oder durch Einmal-Alarme geändert wurden
+	editedAlarms := map[string]bool{}
+
 	var mainWindow *walk.MainWindow
 	var pairTable *walk.TableView
 	var alarmTable *walk.TableView
 	var statusLabel *walk.Label
+	var intervalEdit *walk.NumberEdit
 
 	// Pair hinzufügen Dialog
 	addPairFunc := func() {
 		var dlg *walk.Dialog
 		var fromCombo, toCombo *walk.ComboBox
+		var intervalEdit *walk.NumberEdit
 		var selectedFrom, selectedTo string
+		var selectedInterval float64
 
 		result, err := Dialog{
 			AssignTo: &dlg,
@@ -59,6 +66,11 @@ func openSettingsWindow() {
 							Editable: true,
 							Model:    currencySuggestions,
 						},
+						Label{Text: "Refresh (min, 0 = default):"},
+						NumberEdit{
+							AssignTo: &intervalEdit,
+							Decimals: 1,
+						},
 					},
 				},
 				Composite{
@@ -70,6 +82,7 @@ func openSettingsWindow() {
 							OnClicked: func() {
 								selectedFrom = strings.TrimSpace(fromCombo.Text())
 								selectedTo = strings.TrimSpace(toCombo.Text())
+								selectedInterval = intervalEdit.Value()
 
 								if selectedFrom == "" || selectedTo == "" {
 									walk.MsgBox(dlg, "Validation",
@@ -97,8 +110,9 @@ func openSettingsWindow() {
 
 		if result == walk.DlgCmdOK && selectedFrom != "" && selectedTo != "" {
 			pairModel.items = append(pairModel.items, PairRow{
-				From: strings.ToUpper(selectedFrom),
-				To:   strings.ToUpper(selectedTo),
+				From:           strings.ToUpper(selectedFrom),
+				To:             strings.ToUpper(selectedTo),
+				RefreshSeconds: int(selectedInterval * 60),
 			})
 			pairModel.PublishRowsReset()
 			pairTable.SetCurrentIndex(len(pairModel.items) - 1)
@@ -114,15 +128,29 @@ func openSettingsWindow() {
 		}
 	}
 
+	// Paare der Tabelle (auch ungespeichert) für Ausdrücke
+	tablePairs := func() []CurrencyPair {
+		var pairs []CurrencyPair
+		for _, p := range pairModel.items {
+			pairs = append(pairs, CurrencyPair{
+				From:           p.From,
+				To:             p.To,
+				Direct:         p.Direct,
+				RefreshSeconds: p.RefreshSeconds,
+			})
+		}
+		return pairs
+	}
+	knownPairs := func() []string {
+		configMu.RLock()
+		cfg := currentConfig
+		configMu.RUnlock()
+		cfg.Pairs = tablePairs()
+		return expressionPairs(cfg)
+	}
+
 	// Alarm hinzufügen Dialog
 	addAlarmFunc := func() {
-		var dlg *walk.Dialog
-		var pairEdit *walk.LineEdit
-		var targetEdit *walk.NumberEdit
-		var dirCombo *walk.ComboBox
-
-		directions := []string{"above", "below"}
-
 		defaultPair := ""
 		if pairTable != nil {
 			if idx := pairTable.CurrentIndex(); idx >= 0 && idx < len(pairModel.items) {
@@ -131,100 +159,13 @@ func openSettingsWindow() {
 			}
 		}
 
-		var selectedPair string
-		var selectedTarget float64
-		var selectedDirection string
-
-		result, err := Dialog{
-			AssignTo: &dlg,
-			Title:    "Add Alarm",
-			MinSize:  Size{Width: 300, Height: 180},
-			Layout:   VBox{},
-			Children: []Widget{
-				Composite{
-					Layout: Grid{Columns: 2},
-					Children: []Widget{
-						Label{Text: "Pair (e.g. EUR/CHF):"},
-						LineEdit{
-							AssignTo: &pairEdit,
-							Text:     defaultPair,
-						},
-						Label{Text: "Target:"},
-						NumberEdit{
-							AssignTo: &targetEdit,
-							Decimals: 4,
-						},
-						Label{Text: "Direction:"},
-						ComboBox{
-							AssignTo:     &dirCombo,
-							Model:        directions,
-							CurrentIndex: 0,
-						},
-					},
-				},
-				Composite{
-					Layout: HBox{},
-					Children: []Widget{
-						HSpacer{},
-						PushButton{
-							Text: "Add",
-							OnClicked: func() {
-								selectedPair = strings.TrimSpace(pairEdit.Text())
-								selectedTarget = targetEdit.Value()
-
-								idx := dirCombo.CurrentIndex()
-								if idx >= 0 && idx < len(directions) {
-									selectedDirection = directions[idx]
-								}
-
-								if selectedPair == "" {
-									walk.MsgBox(dlg, "Validation",
-										"Please enter a currency pair (e.g. EUR/CHF).",
-										walk.MsgBoxIconWarning)
-									return
-								}
-								if selectedTarget == 0 {
-									walk.MsgBox(dlg, "Validation",
-										"Please enter a non-zero target.",
-										walk.MsgBoxIconWarning)
-									return
-								}
-								if selectedDirection == "" {
-									walk.MsgBox(dlg, "Validation",
-										"Please select a direction (above/below).",
-										walk.MsgBoxIconWarning)
-									return
-								}
-
-								dlg.Accept()
-							},
-						},
-						PushButton{
-							Text: "Cancel",
-							OnClicked: func() {
-								dlg.Cancel()
-							},
-						},
-					},
-				},
-			},
-		}.Run(mainWindow)
-
-		if err != nil {
-			walk.MsgBox(mainWindow, "Error", "Failed to open dialog: "+err.Error(), walk.MsgBoxIconError)
+		a, ok := runAlarmDialog(mainWindow, "Add Alarm", "Add", Alarm{Pair: defaultPair, Direction: dirAbove}, knownPairs())
+		if !ok {
 			return
 		}
-
-		if result == walk.DlgCmdOK && selectedPair != "" && selectedDirection != "" {
-			alarmModel.items = append(alarmModel.items, AlarmRow{
-				Pair:      normalizeAlarmPair(selectedPair),
-				Target:    selectedTarget,
-				Direction: selectedDirection,
-			})
-			alarmModel.PublishRowsReset()
-			alarmTable.SetCurrentIndex(len(alarmModel.items) - 1)
-
-		}
+		alarmModel.items = append(alarmModel.items, AlarmRow{Alarm: a})
+		alarmModel.PublishRowsReset()
+		alarmTable.SetCurrentIndex(len(alarmModel.items) - 1)
 	}
 
 	// Alarm bearbeiten
@@ -235,114 +176,14 @@ func openSettingsWindow() {
 			return
 		}
 
-		currentAlarm := alarmModel.items[idx]
-
-		var dlg *walk.Dialog
-		var pairEdit *walk.LineEdit
-		var targetEdit *walk.NumberEdit
-		var dirCombo *walk.ComboBox
-
-		directions := []string{"above", "below"}
-		currentDirIndex := 0
-		if strings.ToLower(currentAlarm.Direction) == "below" {
-			currentDirIndex = 1
-		}
-
-		var selectedPair string
-		var selectedTarget float64
-		var selectedDirection string
-
-		result, err := Dialog{
-			AssignTo: &dlg,
-			Title:    "Edit Alarm",
-			MinSize:  Size{Width: 300, Height: 180},
-			Layout:   VBox{},
-			Children: []Widget{
-				Composite{
-					Layout: Grid{Columns: 2},
-					Children: []Widget{
-						Label{Text: "Pair (e.g. EUR/CHF):"},
-						LineEdit{
-							AssignTo: &pairEdit,
-							Text:     currentAlarm.Pair,
-						},
-						Label{Text: "Target:"},
-						NumberEdit{
-							AssignTo: &targetEdit,
-							Value:    currentAlarm.Target,
-							Decimals: 4,
-						},
-						Label{Text: "Direction:"},
-						ComboBox{
-							AssignTo:     &dirCombo,
-							Model:        directions,
-							CurrentIndex: currentDirIndex,
-						},
-					},
-				},
-				Composite{
-					Layout: HBox{},
-					Children: []Widget{
-						HSpacer{},
-						PushButton{
-							Text: "Save",
-							OnClicked: func() {
-								selectedPair = strings.TrimSpace(pairEdit.Text())
-								selectedTarget = targetEdit.Value()
-
-								dirIdx := dirCombo.CurrentIndex()
-								if dirIdx >= 0 && dirIdx < len(directions) {
-									selectedDirection = directions[dirIdx]
-								}
-
-								if selectedPair == "" {
-									walk.MsgBox(dlg, "Validation",
-										"Please enter a currency pair (e.g. EUR/CHF).",
-										walk.MsgBoxIconWarning)
-									return
-								}
-								if selectedTarget == 0 {
-									walk.MsgBox(dlg, "Validation",
-										"Please enter a non-zero target.",
-										walk.MsgBoxIconWarning)
-									return
-								}
-								if selectedDirection == "" {
-									walk.MsgBox(dlg, "Validation",
-										"Please select a direction (above/below).",
-										walk.MsgBoxIconWarning)
-									return
-								}
-
-								dlg.Accept()
-							},
-						},
-						PushButton{
-							Text: "Cancel",
-							OnClicked: func() {
-								dlg.Cancel()
-							},
-						},
-					},
-				},
-			},
-		}.Run(mainWindow)
-
-		if err != nil {
-			walk.MsgBox(mainWindow, "Error", "Failed to open dialog: "+err.Error(), walk.MsgBoxIconError)
+		a, ok := runAlarmDialog(mainWindow, "Edit Alarm", "Save", alarmModel.items[idx].Alarm, knownPairs())
+		if !ok {
 			return
 		}
-
-		if result == walk.DlgCmdOK && selectedPair != "" && selectedDirection != "" {
-			alarmModel.items[idx] = AlarmRow{
-				Pair:      normalizeAlarmPair(selectedPair),
-				Target:    selectedTarget,
-				Direction: selectedDirection,
-			}
-			alarmModel.PublishRowsReset()
-			alarmTable.SetCurrentIndex(idx)
-
-		}
+		alarmModel.items[idx] = AlarmRow{Alarm: a}
+		editedAlarms[a.ID] = true
+		alarmModel.PublishRowsReset()
+		alarmTable.SetCurrentIndex(idx)
 	}
 
 	// Alarm löschen
@@ -356,21 +197,33 @@ func openSettingsWindow() {
 
 	// Speichern
 	saveFunc := func() {
-		var newCfg Config
-
-		for _, p := range pairModel.items {
-			newCfg.Pairs = append(newCfg.Pairs, CurrencyPair{
-				From: p.From,
-				To:   p.To,
-			})
+		// Übrige Einstellungen (Quellen etc.) übernehmen
+		configMu.RLock()
+		newCfg := currentConfig
+		latest := map[string]Alarm{}
+		for _, a := range currentConfig.Alarms {
+			latest[a.ID] = a
 		}
-
-		for _, a := range alarmModel.items {
-			newCfg.Alarms = append(newCfg.Alarms, Alarm{
-				Pair:      a.Pair,
-				Target:    a.Target,
-				Direction: a.Direction,
-			})
+		configMu.RUnlock()
+		newCfg.Pairs = tablePairs()
+		newCfg.Alarms = nil
+		newCfg.RefreshSeconds = int(intervalEdit.Value() * 60)
+
+		for i, a := range alarmModel.items {
+			if cur, ok := latest[a.ID]; ok && a.ID != "" && !editedAlarms[a.ID] {
+				a.Disabled = cur.Disabled
+				a.SnoozeUntil = cur.SnoozeUntil
+				alarmModel.items[i] = a
+			}
+			if a.Direction == dirExpression {
+				if err := validateAlarmExpression(a.Expression, expressionPairs(newCfg)); err != nil {
+					walk.MsgBox(mainWindow, "Validation",
+						"Invalid alarm expression \""+a.Expression+"\": "+err.Error(),
+						walk.MsgBoxIconWarning)
+					return
+				}
+			}
+			newCfg.Alarms = append(newCfg.Alarms, a.Alarm)
 		}
 
 		if err := saveConfig(newCfg); err != nil {
@@ -378,6 +231,17 @@ func openSettingsWindow() {
 			return
 		}
 
+		// Tabelle mit den gespeicherten Alarmen (IDs, Status) abgleichen
+		configMu.RLock()
+		saved := currentConfig.Alarms
+		configMu.RUnlock()
+		alarmModel.items = alarmModel.items[:0]
+		for _, a := range saved {
+			alarmModel.items = append(alarmModel.items, AlarmRow{Alarm: a})
+		}
+		alarmModel.PublishRowsReset()
+		clear(editedAlarms)
+
 		statusLabel.SetText("Saved successfully!")
 		go func() {
 			time.Sleep(2 * time.Second)
@@ -397,71 +261,98 @@ func openSettingsWindow() {
 		AssignTo: &mainWindow,
 		Title:    "FX Tray Settings",
 
-		Size:    Size{Width: 290, Height: 380},
+		Size:    Size{Width: 380, Height: 480},
 		MinSize: Size{Width: 250, Height: 300},
 
 		Layout: VBox{Margins: Margins{Left: 6, Top: 6, Right: 6, Bottom: 6}},
 		Children: []Widget{
-			Label{
-				Text: "Currency Pairs",
-				Font: Font{PointSize: 10, Bold: true},
-			},
-			TableView{
-				AssignTo:         &pairTable,
-				AlternatingRowBG: true,
-				MinSize:          Size{Width: 0, Height: 100},
-				Columns: []TableViewColumn{
-					{Title: "From", Width: 80},
-					{Title: "To", Width: 80},
-				},
-				Model: pairModel,
-			},
-			Composite{
-				Layout: HBox{},
-				Children: []Widget{
-					PushButton{
-						Text:      "Add Pair",
-						OnClicked: addPairFunc,
-					},
-					PushButton{
-						Text:      "Delete Selected",
-						OnClicked: deletePairFunc,
-					},
-					HSpacer{},
-				},
-			},
-			VSpacer{Size: 8},
-			Label{
-				Text: "Alarms",
-				Font: Font{PointSize: 10, Bold: true},
-			},
-			TableView{
-				AssignTo:         &alarmTable,
-				AlternatingRowBG: true,
-				MinSize:          Size{Width: 0, Height: 100},
-				Columns: []TableViewColumn{
-					{Title: "Pair", Width: 100},
-					{Title: "Target", Width: 80},
-					{Title: "Direction", Width: 80},
-				},
-				Model: alarmModel,
-			},
-			Composite{
-				Layout: HBox{},
-				Children: []Widget{
-					PushButton{
-						Text:      "Add Alarm",
-						OnClicked: addAlarmFunc,
-					},
-					PushButton{
-						Text:      "Edit Selected",
-						OnClicked: editAlarmFunc,
-					},
-					PushButton{
-						Text:      "Delete Selected",
-						OnClicked: deleteAlarmFunc,
+			TabWidget{
+				Pages: []TabPage{
+					{
+						Title:  "Settings",
+						Layout: VBox{},
+						Children: []Widget{
+							Label{
+								Text: "Currency Pairs",
+								Font: Font{PointSize: 10, Bold: true},
+							},
+							TableView{
+								AssignTo:         &pairTable,
+								AlternatingRowBG: true,
+								MinSize:          Size{Width: 0, Height: 100},
+								Columns: []TableViewColumn{
+									{Title: "From", Width: 70},
+									{Title: "To", Width: 70},
+									{Title: "Refresh", Width: 70},
+								},
+								Model: pairModel,
+							},
+							Composite{
+								Layout: HBox{},
+								Children: []Widget{
+									PushButton{
+										Text:      "Add Pair",
+										OnClicked: addPairFunc,
+									},
+									PushButton{
+										Text:      "Delete Selected",
+										OnClicked: deletePairFunc,
+									},
+									HSpacer{},
+								},
+							},
+							Composite{
+								Layout: HBox{MarginsZero: true},
+								Children: []Widget{
+									Label{Text: "Refresh every (min):"},
+									NumberEdit{
+										AssignTo: &intervalEdit,
+										Value:    refreshInterval(cfg).Minutes(),
+										Decimals: 1,
+										MinValue: minInterval.Minutes(),
+										MaxValue: 24 * 60,
+									},
+									HSpacer{},
+								},
+							},
+							VSpacer{Size: 8},
+							Label{
+								Text: "Alarms",
+								Font: Font{PointSize: 10, Bold: true},
+							},
+							TableView{
+								AssignTo:         &alarmTable,
+								AlternatingRowBG: true,
+								MinSize:          Size{Width: 0, Height: 100},
+								Columns: []TableViewColumn{
+									{Title: "Pair", Width: 70},
+									{Title: "Target", Width: 70},
+									{Title: "Direction", Width: 110},
+									{Title: "Status", Width: 90},
+								},
+								Model: alarmModel,
+							},
+							Composite{
+								Layout: HBox{},
+								Children: []Widget{
+									PushButton{
+										Text:      "Add Alarm",
+										OnClicked: addAlarmFunc,
+									},
+									PushButton{
+										Text:      "Edit Selected",
+										OnClicked: editAlarmFunc,
+									},
+									PushButton{
+										Text:      "Delete Selected",
+										OnClicked: deleteAlarmFunc,
+									},
+									HSpacer{},
+								},
+							},
+						},
 					},
-					HSpacer{},
+					alarmHistoryPage(&mainWindow),
 				},
 			},
 			VSpacer{Size: 8},